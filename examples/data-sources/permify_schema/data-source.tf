@@ -0,0 +1,11 @@
+data "permify_schema" "current" {
+  tenant_id = "t1"
+}
+
+# The relations of the document entity, by name
+locals {
+  document_relations = {
+    for relation in one([for entity in data.permify_schema.current.entities : entity if entity.name == "document"]).relations :
+    relation.name => relation.types
+  }
+}
@@ -0,0 +1,12 @@
+data "permify_schema_versions" "recent" {
+  tenant_id = "t1"
+  since     = "2025-01-01T00:00:00Z"
+  limit     = 10
+}
+
+check "schema_churn" {
+  assert {
+    condition     = length(data.permify_schema_versions.recent.versions) < 10
+    error_message = "The schema of t1 changed ten times or more since the start of 2025."
+  }
+}
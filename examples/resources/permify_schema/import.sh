@@ -0,0 +1,7 @@
+# Import the latest version of a tenant's schema
+terraform import permify_schema.example my-tenant
+
+# Import a specific version of a tenant's schema.  Refreshes keep that version
+# until Terraform writes a new one, which needs force = true while it is not
+# the tenant's latest version.
+terraform import permify_schema.example my-tenant/cnjn5l8vq6mq2ni8ta6g
@@ -1,13 +1,32 @@
 resource "permify_schema" "heredoc" {
     tenant_id = "test"
     schema = <<EOF
-    entity User {
-        field name: string
+    entity user {}
+
+    entity document {
+        relation owner @user
+        attribute public boolean
+
+        permission view = owner or public
     }
     EOF
 }
 
 resource "permify_schema" "file" {
     tenant_id = "test"
-    schema = file("schema.txt")
-}
\ No newline at end of file
+    schema = file("schema.perm")
+}
+
+# Writes an earlier version of the schema again, to roll back a bad change
+resource "permify_schema" "rollback" {
+    tenant_id = "test"
+    source_version = "cn7k5kv1d9dc73bmgg30"
+}
+
+# Waits until the new version can be read back, for Permify clusters whose
+# nodes pick up schema changes at different times
+resource "permify_schema" "clustered" {
+    tenant_id = "test"
+    schema = file("schema.perm")
+    wait_for_propagation = "30s"
+}
@@ -0,0 +1,27 @@
+resource "permify_schema" "base" {
+    tenant_id = "test"
+    schema = <<EOF
+    entity user {}
+
+    entity document {
+        relation owner @user
+    }
+    EOF
+
+    # The partial below adds to this schema.
+    lifecycle {
+        ignore_changes = [schema]
+    }
+}
+
+# Adds members to the document entity of the schema above, leaving the rest
+# of it to other resources.
+resource "permify_schema_partial" "sharing" {
+    tenant_id = permify_schema.base.tenant_id
+    schema = <<EOF
+    entity document {
+        relation viewer @user
+        permission view = owner or viewer
+    }
+    EOF
+}
@@ -0,0 +1,196 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	permify_payload "buf.build/gen/go/permifyco/permify/protocolbuffers/go/base/v1"
+	permify_grpc "github.com/Permify/permify-go/grpc"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var _ datasource.DataSource = &schemaDataSource{}
+var _ datasource.DataSourceWithConfigure = &schemaDataSource{}
+
+func NewSchemaDataSource() datasource.DataSource {
+	return &schemaDataSource{}
+}
+
+type schemaDataSource struct {
+	client        *permify_grpc.Client
+	configUnknown bool
+}
+
+func (d *schemaDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_schema"
+}
+
+func (d *schemaDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "A version of a tenant's schema, with its entities and their members",
+		Attributes: map[string]schema.Attribute{
+			"tenant_id": schema.StringAttribute{
+				MarkdownDescription: "The ID of the tenant the schema belongs to",
+				Required:            true,
+			},
+			"schema_version": schema.StringAttribute{
+				MarkdownDescription: "The version of the schema to read.  Defaults to the latest version, and is set to it " +
+					"when omitted.",
+				Optional: true,
+				Computed: true,
+			},
+			"schema": schema.StringAttribute{
+				MarkdownDescription: "The schema as Permify compiled it, with declarations sorted by name",
+				Computed:            true,
+			},
+			"entities": schema.ListNestedAttribute{
+				MarkdownDescription: "The entities of the schema, sorted by name",
+				Computed:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"name": schema.StringAttribute{
+							MarkdownDescription: "The name of the entity",
+							Computed:            true,
+						},
+						"relations": schema.ListNestedAttribute{
+							MarkdownDescription: "The relations of the entity",
+							Computed:            true,
+							NestedObject: schema.NestedAttributeObject{
+								Attributes: map[string]schema.Attribute{
+									"name": schema.StringAttribute{
+										MarkdownDescription: "The name of the relation",
+										Computed:            true,
+									},
+									"types": schema.ListAttribute{
+										MarkdownDescription: "The subjects the relation accepts, such as `user` or `organization#member`",
+										ElementType:         types.StringType,
+										Computed:            true,
+									},
+								},
+							},
+						},
+						"attributes": schema.ListNestedAttribute{
+							MarkdownDescription: "The attributes of the entity",
+							Computed:            true,
+							NestedObject: schema.NestedAttributeObject{
+								Attributes: map[string]schema.Attribute{
+									"name": schema.StringAttribute{
+										MarkdownDescription: "The name of the attribute",
+										Computed:            true,
+									},
+									"type": schema.StringAttribute{
+										MarkdownDescription: "The type of the attribute, such as `boolean` or `string[]`",
+										Computed:            true,
+									},
+								},
+							},
+						},
+						"permissions": schema.ListNestedAttribute{
+							MarkdownDescription: "The permissions of the entity, actions included",
+							Computed:            true,
+							NestedObject: schema.NestedAttributeObject{
+								Attributes: map[string]schema.Attribute{
+									"name": schema.StringAttribute{
+										MarkdownDescription: "The name of the permission",
+										Computed:            true,
+									},
+									"expression": schema.StringAttribute{
+										MarkdownDescription: "The expression granting the permission, such as `owner or parent.admin`",
+										Computed:            true,
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (d *schemaDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	data, ok := req.ProviderData.(*providerData)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *providerData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	d.client = data.client
+	d.configUnknown = data.configUnknown
+}
+
+func (d *schemaDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	if d.configUnknown {
+		resp.Diagnostics.Append(configUnknownDiagnostic())
+		return
+	}
+	if d.client == nil {
+		resp.Diagnostics.Append(clientNotConfiguredDiagnostic())
+		return
+	}
+
+	var data SchemaDataSourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	tenantID := data.TenantID.ValueString()
+
+	version := data.SchemaVersion.ValueString()
+	if data.SchemaVersion.IsNull() {
+		list, err := d.client.Schema.List(ctx, &permify_payload.SchemaListRequest{
+			TenantId: tenantID,
+			PageSize: 1,
+		})
+		if status.Code(err) != codes.NotFound && err != nil {
+			resp.Diagnostics.AddError("Error reading Permify Schema", err.Error())
+			return
+		}
+		if err != nil || list.Head == "" {
+			resp.Diagnostics.AddError("Permify Schema not found", fmt.Sprintf("Tenant %s has no schema.", tenantID))
+			return
+		}
+		version = list.Head
+	}
+
+	result, err := d.client.Schema.Read(ctx, &permify_payload.SchemaReadRequest{
+		TenantId: tenantID,
+		Metadata: &permify_payload.SchemaReadRequestMetadata{SchemaVersion: version},
+	})
+	if status.Code(err) == codes.NotFound {
+		resp.Diagnostics.AddError("Permify Schema not found", fmt.Sprintf("Tenant %s has no schema version %s.", tenantID, version))
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError("Error reading Permify Schema", err.Error())
+		return
+	}
+	decompiled, err := FromSchemaDefinition(result.Schema)
+	if err != nil {
+		resp.Diagnostics.AddError("Error reading Permify Schema", fmt.Sprintf("Schema version %s cannot be decompiled: %s", version, err))
+		return
+	}
+
+	data.SchemaVersion = types.StringValue(version)
+	data.fromSchema(decompiled)
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+
+	tflog.Debug(ctx, "Finished reading Permify Schema data source", map[string]any{"success": true})
+}
@@ -0,0 +1,152 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	permify_payload "buf.build/gen/go/permifyco/permify/protocolbuffers/go/base/v1"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccSchemaDataSource(t *testing.T) {
+	providerConfig := initPermify(t)
+
+	resource.Test(t, resource.TestCase{
+		IsUnitTest: true,
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccSchemaDataSourceConfig(providerConfig),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrPair("data.permify_schema.latest", "schema_version", "permify_schema.test", "schema_version"),
+					resource.TestCheckResourceAttr("data.permify_schema.latest", "entities.#", "3"),
+					resource.TestCheckResourceAttr("data.permify_schema.latest", "entities.0.name", "organization"),
+					resource.TestCheckResourceAttr("data.permify_schema.latest", "entities.0.relations.0.name", "admin"),
+					resource.TestCheckResourceAttr("data.permify_schema.latest", "entities.1.relations.0.types.#", "2"),
+					resource.TestCheckResourceAttrPair("data.permify_schema.pinned", "schema", "data.permify_schema.latest", "schema"),
+				),
+			},
+		},
+	})
+}
+
+func testAccSchemaDataSourceConfig(providerConfig string) string {
+	return providerConfig + fmt.Sprintf(`
+resource "permify_schema" "test" {
+  tenant_id = "t1"
+  schema = %[1]q
+}
+
+data "permify_schema" "latest" {
+  tenant_id = permify_schema.test.tenant_id
+}
+
+data "permify_schema" "pinned" {
+  tenant_id = permify_schema.test.tenant_id
+  schema_version = permify_schema.test.schema_version
+}
+`, testSchemaDefinition)
+}
+
+func TestSchemaDataSourceRead(t *testing.T) {
+	ctx := context.Background()
+	const tenantID = "data"
+
+	read := func(t *testing.T, fake *fakePermify, version string) (datasource.ReadResponse, SchemaDataSourceModel) {
+		d := NewSchemaDataSource()
+		d.(datasource.DataSourceWithConfigure).Configure(ctx, datasource.ConfigureRequest{
+			ProviderData: &providerData{client: newRetryTestClient(t, fake, 0)},
+		}, &datasource.ConfigureResponse{})
+
+		var schemaResp datasource.SchemaResponse
+		d.Schema(ctx, datasource.SchemaRequest{}, &schemaResp)
+		schemaType := schemaResp.Schema.Type().TerraformType(ctx).(tftypes.Object)
+		values := map[string]tftypes.Value{}
+		for name, attributeType := range schemaType.AttributeTypes {
+			values[name] = tftypes.NewValue(attributeType, nil)
+		}
+		values["tenant_id"] = tftypes.NewValue(tftypes.String, tenantID)
+		if version != "" {
+			values["schema_version"] = tftypes.NewValue(tftypes.String, version)
+		}
+		config := tfsdk.Config{Schema: schemaResp.Schema, Raw: tftypes.NewValue(schemaType, values)}
+
+		resp := datasource.ReadResponse{State: tfsdk.State{Schema: schemaResp.Schema, Raw: tftypes.NewValue(schemaType, nil)}}
+		d.Read(ctx, datasource.ReadRequest{Config: config}, &resp)
+		var model SchemaDataSourceModel
+		if !resp.Diagnostics.HasError() {
+			diags := resp.State.Get(ctx, &model)
+			require.False(t, diags.HasError(), "%v", diags)
+		}
+		return resp, model
+	}
+
+	t.Run("latest", func(t *testing.T) {
+		fake := startFakePermify(t)
+		fake.schema.push(tenantID, "", &permify_payload.SchemaDefinition{})
+		head := fake.schema.push(tenantID, testSchemaDefinition, testCompiledSchemaDefinition(t))
+
+		resp, model := read(t, fake, "")
+		require.False(t, resp.Diagnostics.HasError(), "%v", resp.Diagnostics)
+		require.Equal(t, head, model.SchemaVersion.ValueString())
+		require.Equal(t, testCompiledSchema, model.Schema.ValueString())
+		require.Equal(t, SchemaEntityModel{
+			Name: "organization",
+			Relations: []SchemaRelationModel{
+				{Name: "admin", Types: []string{"user"}},
+				{Name: "member", Types: []string{"user", "organization#admin"}},
+				{Name: "viewer", Types: []string{"user"}},
+			},
+			Attributes: []SchemaAttributeModel{
+				{Name: "credit", Type: "integer"},
+				{Name: "tags", Type: "string[]"},
+			},
+			Permissions: []SchemaPermissionModel{
+				{Name: "edit", Expression: "admin and check_credit(credit)"},
+				{Name: "view", Expression: "admin or member or viewer"},
+			},
+		}, model.Entities[0])
+		require.Equal(t, SchemaEntityModel{
+			Name:        "user",
+			Relations:   []SchemaRelationModel{},
+			Attributes:  []SchemaAttributeModel{},
+			Permissions: []SchemaPermissionModel{},
+		}, model.Entities[2])
+	})
+
+	t.Run("pinned version", func(t *testing.T) {
+		fake := startFakePermify(t)
+		pinned := fake.schema.push(tenantID, "", &permify_payload.SchemaDefinition{
+			EntityDefinitions: map[string]*permify_payload.EntityDefinition{"user": {Name: "user"}},
+		})
+		fake.schema.push(tenantID, testSchemaDefinition, testCompiledSchemaDefinition(t))
+
+		resp, model := read(t, fake, pinned)
+		require.False(t, resp.Diagnostics.HasError(), "%v", resp.Diagnostics)
+		require.Equal(t, pinned, model.SchemaVersion.ValueString())
+		require.Equal(t, "entity user {}\n", model.Schema.ValueString())
+	})
+
+	t.Run("unknown version", func(t *testing.T) {
+		fake := startFakePermify(t)
+		fake.schema.push(tenantID, testSchemaDefinition, testCompiledSchemaDefinition(t))
+
+		resp, _ := read(t, fake, "v9999")
+		require.True(t, resp.Diagnostics.HasError())
+		require.Equal(t, "Permify Schema not found", resp.Diagnostics.Errors()[0].Summary())
+	})
+
+	t.Run("no schema", func(t *testing.T) {
+		resp, _ := read(t, startFakePermify(t), "")
+		require.True(t, resp.Diagnostics.HasError())
+		require.Equal(t, "Permify Schema not found", resp.Diagnostics.Errors()[0].Summary())
+	})
+}
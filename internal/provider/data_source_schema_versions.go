@@ -0,0 +1,139 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	permify_grpc "github.com/Permify/permify-go/grpc"
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+var _ datasource.DataSource = &schemaVersionsDataSource{}
+var _ datasource.DataSourceWithConfigure = &schemaVersionsDataSource{}
+
+func NewSchemaVersionsDataSource() datasource.DataSource {
+	return &schemaVersionsDataSource{}
+}
+
+type schemaVersionsDataSource struct {
+	client        *permify_grpc.Client
+	configUnknown bool
+}
+
+func (d *schemaVersionsDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_schema_versions"
+}
+
+func (d *schemaVersionsDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "The versions of a tenant's schema, newest first",
+		Attributes: map[string]schema.Attribute{
+			"tenant_id": schema.StringAttribute{
+				MarkdownDescription: "The ID of the tenant the schema belongs to",
+				Required:            true,
+			},
+			"limit": schema.Int64Attribute{
+				MarkdownDescription: "The maximum number of versions to return.  Defaults to all of them.",
+				Optional:            true,
+				Validators: []validator.Int64{
+					int64validator.AtLeast(1),
+				},
+			},
+			"since": schema.StringAttribute{
+				MarkdownDescription: "Only return versions created at or after this RFC 3339 timestamp, such as `2025-01-01T00:00:00Z`",
+				Optional:            true,
+			},
+			"head": schema.StringAttribute{
+				MarkdownDescription: "The latest version of the schema, which is empty when the tenant has no schema",
+				Computed:            true,
+			},
+			"versions": schema.ListNestedAttribute{
+				MarkdownDescription: "The versions of the schema, newest first",
+				Computed:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"version": schema.StringAttribute{
+							MarkdownDescription: "The schema version",
+							Computed:            true,
+						},
+						"created_at": schema.StringAttribute{
+							MarkdownDescription: "Created timestamp",
+							Computed:            true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (d *schemaVersionsDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	data, ok := req.ProviderData.(*providerData)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *providerData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	d.client = data.client
+	d.configUnknown = data.configUnknown
+}
+
+func (d *schemaVersionsDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	if d.configUnknown {
+		resp.Diagnostics.Append(configUnknownDiagnostic())
+		return
+	}
+	if d.client == nil {
+		resp.Diagnostics.Append(clientNotConfiguredDiagnostic())
+		return
+	}
+
+	var data SchemaVersionsDataSourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var since time.Time
+	if !data.Since.IsNull() {
+		var err error
+		if since, err = time.Parse(time.RFC3339, data.Since.ValueString()); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("since"), "Invalid timestamp", fmt.Sprintf("since must be an RFC 3339 timestamp: %s", err))
+			return
+		}
+	}
+
+	head, versions, err := listSchemaVersions(ctx, d.client, data.TenantID.ValueString(), int(data.Limit.ValueInt64()), since)
+	if err != nil {
+		resp.Diagnostics.AddError("Error listing Permify Schema versions", err.Error())
+		return
+	}
+
+	data.Head = types.StringValue(head)
+	data.Versions = []SchemaVersionModel{}
+	for _, version := range versions {
+		data.Versions = append(data.Versions, SchemaVersionModel{Version: version.Version, CreatedAt: version.CreatedAt})
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+
+	tflog.Debug(ctx, "Finished reading Permify Schema versions data source", map[string]any{"success": true, "versions": len(data.Versions)})
+}
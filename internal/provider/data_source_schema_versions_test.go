@@ -0,0 +1,112 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	permify_payload "buf.build/gen/go/permifyco/permify/protocolbuffers/go/base/v1"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccSchemaVersionsDataSource(t *testing.T) {
+	providerConfig := initPermify(t)
+
+	resource.Test(t, resource.TestCase{
+		IsUnitTest: true,
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccSchemaVersionsDataSourceConfig(providerConfig),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrPair("data.permify_schema_versions.all", "head", "permify_schema.test", "schema_version"),
+					resource.TestCheckResourceAttrPair("data.permify_schema_versions.all", "versions.0.version", "permify_schema.test", "schema_version"),
+					resource.TestCheckResourceAttrSet("data.permify_schema_versions.all", "versions.0.created_at"),
+					resource.TestCheckResourceAttr("data.permify_schema_versions.latest", "versions.#", "1"),
+					resource.TestCheckResourceAttr("data.permify_schema_versions.future", "versions.#", "0"),
+				),
+			},
+		},
+	})
+}
+
+func testAccSchemaVersionsDataSourceConfig(providerConfig string) string {
+	return providerConfig + fmt.Sprintf(`
+resource "permify_schema" "test" {
+  tenant_id = "t1"
+  schema = %[1]q
+}
+
+data "permify_schema_versions" "all" {
+  tenant_id = permify_schema.test.tenant_id
+}
+
+data "permify_schema_versions" "latest" {
+  tenant_id = permify_schema.test.tenant_id
+  limit = 1
+}
+
+data "permify_schema_versions" "future" {
+  tenant_id = permify_schema.test.tenant_id
+  since = "2999-01-01T00:00:00Z"
+}
+`, testSchemaDefinition)
+}
+
+func TestListSchemaVersions(t *testing.T) {
+	ctx := context.Background()
+	fake := startFakePermify(t)
+	client := newRetryTestClient(t, fake, 0)
+
+	var pushed []string
+	for i := 0; i < 150; i++ {
+		pushed = append(pushed, fake.schema.push("history", "", &permify_payload.SchemaDefinition{}))
+	}
+	newest := func(n int) []string {
+		var versions []string
+		for i := len(pushed) - 1; i >= len(pushed)-n; i-- {
+			versions = append(versions, pushed[i])
+		}
+		return versions
+	}
+	names := func(versions []*permify_payload.SchemaList) []string {
+		var result []string
+		for _, version := range versions {
+			result = append(result, version.Version)
+		}
+		return result
+	}
+
+	t.Run("all pages", func(t *testing.T) {
+		head, versions, err := listSchemaVersions(ctx, client, "history", 0, time.Time{})
+		require.NoError(t, err)
+		require.Equal(t, pushed[len(pushed)-1], head)
+		require.Equal(t, newest(150), names(versions))
+	})
+
+	t.Run("limit", func(t *testing.T) {
+		_, versions, err := listSchemaVersions(ctx, client, "history", 120, time.Time{})
+		require.NoError(t, err)
+		require.Equal(t, newest(120), names(versions))
+	})
+
+	t.Run("since", func(t *testing.T) {
+		// The fake creates version n at n seconds past 2025, so this is the 140th.
+		since := time.Date(2025, 1, 1, 0, 0, 140, 0, time.UTC)
+		_, listed, err := listSchemaVersions(ctx, client, "history", 0, since)
+		require.NoError(t, err)
+		require.Equal(t, newest(11), names(listed))
+	})
+
+	t.Run("no schema", func(t *testing.T) {
+		head, versions, err := listSchemaVersions(ctx, client, "empty", 0, time.Time{})
+		require.NoError(t, err)
+		require.Empty(t, head)
+		require.Empty(t, versions)
+	})
+}
@@ -19,7 +19,8 @@ func NewTenantDataSource() datasource.DataSource {
 }
 
 type tenantDataSource struct {
-	client *permify_grpc.Client
+	client        *permify_grpc.Client
+	configUnknown bool
 }
 
 func (d *tenantDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
@@ -52,21 +53,31 @@ func (d *tenantDataSource) Configure(ctx context.Context, req datasource.Configu
 		return
 	}
 
-	client, ok := req.ProviderData.(*permify_grpc.Client)
+	data, ok := req.ProviderData.(*providerData)
 
 	if !ok {
 		resp.Diagnostics.AddError(
 			"Unexpected Data Source Configure Type",
-			fmt.Sprintf("Expected *permify_grpc.Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+			fmt.Sprintf("Expected *providerData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
 		)
 
 		return
 	}
 
-	d.client = client
+	d.client = data.client
+	d.configUnknown = data.configUnknown
 }
 
 func (d *tenantDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	if d.configUnknown {
+		resp.Diagnostics.Append(configUnknownDiagnostic())
+		return
+	}
+	if d.client == nil {
+		resp.Diagnostics.Append(clientNotConfiguredDiagnostic())
+		return
+	}
+
 	var data TenantModel
 
 	// Read Terraform configuration data into the model
@@ -0,0 +1,666 @@
+package provider
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"slices"
+	"sort"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"buf.build/gen/go/permifyco/permify/grpc/go/base/v1/basev1grpc"
+	permify_payload "buf.build/gen/go/permifyco/permify/protocolbuffers/go/base/v1"
+	"github.com/google/cel-go/cel"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// fakePermify is an in-process stand-in for the Permify gRPC API, used by tests
+// that need to control the transport or inspect what the provider sends.
+type fakePermify struct {
+	endpoint string
+	tenancy  *fakeTenancyServer
+	schema   *fakeSchemaServer
+	data     *fakeDataServer
+	watch    *fakeWatchServer
+	health   *health.Server
+
+	mu       sync.Mutex
+	requests []metadata.MD
+}
+
+func startFakePermify(t *testing.T, opts ...grpc.ServerOption) *fakePermify {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	fake := &fakePermify{
+		endpoint: listener.Addr().String(),
+		tenancy:  &fakeTenancyServer{tenants: map[string]*permify_payload.Tenant{}},
+		schema:   &fakeSchemaServer{versions: map[string][]fakeSchemaVersion{}},
+		data:     &fakeDataServer{},
+		watch:    &fakeWatchServer{},
+		health:   health.NewServer(),
+	}
+
+	opts = append(opts,
+		grpc.ChainUnaryInterceptor(func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			fake.record(ctx)
+			return handler(ctx, req)
+		}),
+		grpc.ChainStreamInterceptor(func(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			fake.record(stream.Context())
+			return handler(srv, stream)
+		}),
+	)
+	server := grpc.NewServer(opts...)
+	basev1grpc.RegisterTenancyServer(server, fake.tenancy)
+	basev1grpc.RegisterSchemaServer(server, fake.schema)
+	basev1grpc.RegisterDataServer(server, fake.data)
+	basev1grpc.RegisterWatchServer(server, fake.watch)
+	healthpb.RegisterHealthServer(server, fake.health)
+
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	t.Cleanup(server.Stop)
+
+	return fake
+}
+
+func (f *fakePermify) record(ctx context.Context) {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.requests = append(f.requests, md)
+}
+
+// received returns the incoming metadata of every call the server has handled.
+func (f *fakePermify) received() []metadata.MD {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]metadata.MD(nil), f.requests...)
+}
+
+type fakeTenancyServer struct {
+	basev1grpc.UnimplementedTenancyServer
+
+	mu      sync.Mutex
+	tenants map[string]*permify_payload.Tenant
+}
+
+func (s *fakeTenancyServer) Create(ctx context.Context, req *permify_payload.TenantCreateRequest) (*permify_payload.TenantCreateResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.tenants[req.Id]; exists {
+		return nil, status.Errorf(codes.AlreadyExists, "tenant %s already exists", req.Id)
+	}
+	tenant := &permify_payload.Tenant{
+		Id:        req.Id,
+		Name:      req.Name,
+		CreatedAt: timestamppb.New(time.Now().Truncate(time.Second)),
+	}
+	s.tenants[req.Id] = tenant
+	return &permify_payload.TenantCreateResponse{Tenant: tenant}, nil
+}
+
+func (s *fakeTenancyServer) Delete(ctx context.Context, req *permify_payload.TenantDeleteRequest) (*permify_payload.TenantDeleteResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.tenants, req.Id)
+	return &permify_payload.TenantDeleteResponse{TenantId: req.Id}, nil
+}
+
+func (s *fakeTenancyServer) List(ctx context.Context, req *permify_payload.TenantListRequest) (*permify_payload.TenantListResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tenants := make([]*permify_payload.Tenant, 0, len(s.tenants))
+	for _, tenant := range s.tenants {
+		tenants = append(tenants, tenant)
+	}
+	sort.Slice(tenants, func(i, j int) bool { return tenants[i].Id < tenants[j].Id })
+	return &permify_payload.TenantListResponse{Tenants: tenants}, nil
+}
+
+// fakeSchemaServer keeps the schema versions of every tenant.  Versions are
+// pushed with their compiled definition by the tests, or compiled by
+// fakeCompile when they are written through the API.
+type fakeSchemaServer struct {
+	basev1grpc.UnimplementedSchemaServer
+
+	mu       sync.Mutex
+	serial   int
+	versions map[string][]fakeSchemaVersion
+	// staleReads is how many reads of a given version fail as not found, as
+	// they would on a node that has not caught up with the latest write.
+	staleReads int
+}
+
+type fakeSchemaVersion struct {
+	version    string
+	createdAt  string
+	text       string
+	definition *permify_payload.SchemaDefinition
+}
+
+// push adds a schema version to the tenant and returns it.
+func (s *fakeSchemaServer) push(tenantID string, text string, definition *permify_payload.SchemaDefinition) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.serial++
+	version := fmt.Sprintf("v%04d", s.serial)
+	s.versions[tenantID] = append(s.versions[tenantID], fakeSchemaVersion{
+		version:    version,
+		createdAt:  time.Date(2025, 1, 1, 0, 0, s.serial, 0, time.UTC).Format(time.RFC3339),
+		text:       text,
+		definition: definition,
+	})
+	return version
+}
+
+// head returns the latest schema version of the tenant.
+func (s *fakeSchemaServer) head(tenantID string) (fakeSchemaVersion, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	versions := s.versions[tenantID]
+	if len(versions) == 0 {
+		return fakeSchemaVersion{}, false
+	}
+	return versions[len(versions)-1], true
+}
+
+func (s *fakeSchemaServer) Write(ctx context.Context, req *permify_payload.SchemaWriteRequest) (*permify_payload.SchemaWriteResponse, error) {
+	schema, err := parseSchema(req.Schema)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	definition, err := fakeCompile(schema)
+	if err != nil {
+		return nil, err
+	}
+	version := s.push(req.TenantId, req.Schema, definition)
+	return &permify_payload.SchemaWriteResponse{SchemaVersion: version}, nil
+}
+
+// PartialWrite applies the partials to the version named in the metadata, or
+// to the head, as Permify does.
+func (s *fakeSchemaServer) PartialWrite(ctx context.Context, req *permify_payload.SchemaPartialWriteRequest) (*permify_payload.SchemaPartialWriteResponse, error) {
+	read, err := s.Read(ctx, &permify_payload.SchemaReadRequest{
+		TenantId: req.TenantId,
+		Metadata: &permify_payload.SchemaReadRequestMetadata{SchemaVersion: req.GetMetadata().GetSchemaVersion()},
+	})
+	if err != nil {
+		return nil, err
+	}
+	schema, err := FromSchemaDefinition(read.Schema)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	for _, name := range sortedKeys(req.Partials) {
+		entity := schema.entity(name)
+		if entity == nil {
+			return nil, status.Errorf(codes.NotFound, "entity %s not found", name)
+		}
+		partials := req.Partials[name]
+		for _, member := range partials.Delete {
+			if !fakeRemoveMember(entity, member) {
+				return nil, status.Errorf(codes.NotFound, "%s of entity %s not found", member, name)
+			}
+		}
+		for _, statement := range append(append([]string(nil), partials.Update...), partials.Write...) {
+			parsed, err := parseSchema(fmt.Sprintf("entity %s {\n%s\n}", name, statement))
+			if err != nil {
+				return nil, status.Error(codes.InvalidArgument, err.Error())
+			}
+			member := parsed.Entities[0]
+			for _, relation := range member.Relations {
+				fakeRemoveMember(entity, relation.Name)
+				entity.Relations = append(entity.Relations, relation)
+			}
+			for _, attribute := range member.Attributes {
+				fakeRemoveMember(entity, attribute.Name)
+				entity.Attributes = append(entity.Attributes, attribute)
+			}
+			for _, permission := range member.Permissions {
+				fakeRemoveMember(entity, permission.Name)
+				entity.Permissions = append(entity.Permissions, permission)
+			}
+		}
+	}
+
+	definition, err := fakeCompile(schema)
+	if err != nil {
+		return nil, err
+	}
+	version := s.push(req.TenantId, schema.String(), definition)
+	return &permify_payload.SchemaPartialWriteResponse{SchemaVersion: version}, nil
+}
+
+func fakeRemoveMember(entity *dslEntity, name string) bool {
+	for i, relation := range entity.Relations {
+		if relation.Name == name {
+			entity.Relations = append(entity.Relations[:i], entity.Relations[i+1:]...)
+			return true
+		}
+	}
+	for i, attribute := range entity.Attributes {
+		if attribute.Name == name {
+			entity.Attributes = append(entity.Attributes[:i], entity.Attributes[i+1:]...)
+			return true
+		}
+	}
+	for i, permission := range entity.Permissions {
+		if permission.Name == name {
+			entity.Permissions = append(entity.Permissions[:i], entity.Permissions[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// fakeCompile compiles a schema the way Permify does, after checking it with
+// checkSchema's rules.  It only produces what FromSchemaDefinition reads.
+func fakeCompile(schema *dslSchema) (*permify_payload.SchemaDefinition, error) {
+	if errs := schema.check(); len(errs) > 0 {
+		return nil, status.Error(codes.InvalidArgument, errs[0].Error())
+	}
+
+	definition := &permify_payload.SchemaDefinition{
+		EntityDefinitions: map[string]*permify_payload.EntityDefinition{},
+		RuleDefinitions:   map[string]*permify_payload.RuleDefinition{},
+	}
+	attributeTypeValues := map[string]permify_payload.AttributeType{}
+	for value, name := range attributeTypes {
+		attributeTypeValues[name] = value
+	}
+
+	for _, rule := range schema.Rules {
+		compiled := &permify_payload.RuleDefinition{Name: rule.Name, Arguments: map[string]permify_payload.AttributeType{}}
+		for _, argument := range rule.Arguments {
+			compiled.Arguments[argument.Name] = attributeTypeValues[argument.Type]
+		}
+		env, err := ruleEnv(rule)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		ast, issues := env.Compile(rule.Body)
+		if issues != nil && issues.Err() != nil {
+			return nil, status.Error(codes.InvalidArgument, issues.Err().Error())
+		}
+		if compiled.Expression, err = cel.AstToCheckedExpr(ast); err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		definition.RuleDefinitions[rule.Name] = compiled
+	}
+
+	for _, entity := range schema.Entities {
+		compiled := &permify_payload.EntityDefinition{
+			Name:        entity.Name,
+			Relations:   map[string]*permify_payload.RelationDefinition{},
+			Permissions: map[string]*permify_payload.PermissionDefinition{},
+			Attributes:  map[string]*permify_payload.AttributeDefinition{},
+		}
+		for _, relation := range entity.Relations {
+			references := []*permify_payload.RelationReference{}
+			for _, relationType := range relation.Types {
+				references = append(references, &permify_payload.RelationReference{Type: relationType.Entity, Relation: relationType.Relation})
+			}
+			compiled.Relations[relation.Name] = &permify_payload.RelationDefinition{Name: relation.Name, RelationReferences: references}
+		}
+		for _, attribute := range entity.Attributes {
+			compiled.Attributes[attribute.Name] = &permify_payload.AttributeDefinition{Name: attribute.Name, Type: attributeTypeValues[attribute.Type]}
+		}
+		for _, permission := range entity.Permissions {
+			compiled.Permissions[permission.Name] = &permify_payload.PermissionDefinition{Name: permission.Name, Child: fakeCompileExpr(entity, permission.Expr)}
+		}
+		definition.EntityDefinitions[entity.Name] = compiled
+	}
+
+	return definition, nil
+}
+
+func fakeCompileExpr(entity *dslEntity, expr dslExpr) *permify_payload.Child {
+	leaf := func(leaf *permify_payload.Leaf) *permify_payload.Child {
+		return &permify_payload.Child{Type: &permify_payload.Child_Leaf{Leaf: leaf}}
+	}
+	switch expr := expr.(type) {
+	case dslRewrite:
+		rewrite := &permify_payload.Rewrite{}
+		for operation, operator := range rewriteOperators {
+			if operator == expr.Operator {
+				rewrite.RewriteOperation = operation
+			}
+		}
+		for _, child := range expr.Children {
+			rewrite.Children = append(rewrite.Children, fakeCompileExpr(entity, child))
+		}
+		return &permify_payload.Child{Type: &permify_payload.Child_Rewrite{Rewrite: rewrite}}
+	case dslCall:
+		call := &permify_payload.Call{RuleName: expr.Rule}
+		for _, argument := range expr.Arguments {
+			call.Arguments = append(call.Arguments, &permify_payload.Argument{Type: &permify_payload.Argument_ComputedAttribute{
+				ComputedAttribute: &permify_payload.ComputedAttribute{Name: argument},
+			}})
+		}
+		return leaf(&permify_payload.Leaf{Type: &permify_payload.Leaf_Call{Call: call}})
+	case dslIdent:
+		if len(expr.Parts) == 2 {
+			return leaf(&permify_payload.Leaf{Type: &permify_payload.Leaf_TupleToUserSet{TupleToUserSet: &permify_payload.TupleToUserSet{
+				TupleSet: &permify_payload.TupleSet{Relation: expr.Parts[0]},
+				Computed: &permify_payload.ComputedUserSet{Relation: expr.Parts[1]},
+			}}})
+		}
+		if entity.attribute(expr.Parts[0]) != nil {
+			return leaf(&permify_payload.Leaf{Type: &permify_payload.Leaf_ComputedAttribute{
+				ComputedAttribute: &permify_payload.ComputedAttribute{Name: expr.Parts[0]},
+			}})
+		}
+		return leaf(&permify_payload.Leaf{Type: &permify_payload.Leaf_ComputedUserSet{
+			ComputedUserSet: &permify_payload.ComputedUserSet{Relation: expr.Parts[0]},
+		}})
+	}
+	return nil
+}
+
+func (s *fakeSchemaServer) Read(ctx context.Context, req *permify_payload.SchemaReadRequest) (*permify_payload.SchemaReadResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	versions := s.versions[req.TenantId]
+	if len(versions) == 0 {
+		return nil, status.Errorf(codes.NotFound, "no schema for tenant %s", req.TenantId)
+	}
+	want := req.GetMetadata().GetSchemaVersion()
+	if want == "" {
+		return &permify_payload.SchemaReadResponse{Schema: versions[len(versions)-1].definition}, nil
+	}
+	if s.staleReads > 0 {
+		s.staleReads--
+		return nil, status.Errorf(codes.NotFound, "schema version %s not found", want)
+	}
+	for _, version := range versions {
+		if version.version == want {
+			return &permify_payload.SchemaReadResponse{Schema: version.definition}, nil
+		}
+	}
+	return nil, status.Errorf(codes.NotFound, "schema version %s not found", want)
+}
+
+// List returns the newest versions first, paging with the index of the next
+// version as the continuous token.
+func (s *fakeSchemaServer) List(ctx context.Context, req *permify_payload.SchemaListRequest) (*permify_payload.SchemaListResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	versions := s.versions[req.TenantId]
+	resp := &permify_payload.SchemaListResponse{}
+	if len(versions) > 0 {
+		resp.Head = versions[len(versions)-1].version
+	}
+	start := 0
+	if req.ContinuousToken != "" {
+		start, _ = strconv.Atoi(req.ContinuousToken)
+	}
+	for i := start; i < len(versions); i++ {
+		if len(resp.Schemas) == int(req.PageSize) {
+			resp.ContinuousToken = strconv.Itoa(i)
+			break
+		}
+		version := versions[len(versions)-1-i]
+		resp.Schemas = append(resp.Schemas, &permify_payload.SchemaList{Version: version.version, CreatedAt: version.createdAt})
+	}
+	return resp, nil
+}
+
+// fakeDataServer holds relationships and attributes written by the tests,
+// and reads the first page of those matching a filter.
+type fakeDataServer struct {
+	basev1grpc.UnimplementedDataServer
+
+	mu         sync.Mutex
+	tuples     map[string][]*permify_payload.Tuple
+	attributes map[string][]*permify_payload.Attribute
+}
+
+func (s *fakeDataServer) add(tenantID string, tuples []*permify_payload.Tuple, attributes []*permify_payload.Attribute) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.tuples == nil {
+		s.tuples = map[string][]*permify_payload.Tuple{}
+		s.attributes = map[string][]*permify_payload.Attribute{}
+	}
+	s.tuples[tenantID] = append(s.tuples[tenantID], tuples...)
+	s.attributes[tenantID] = append(s.attributes[tenantID], attributes...)
+}
+
+func fakeEntityMatches(filter *permify_payload.EntityFilter, entity *permify_payload.Entity) bool {
+	if filter.GetType() != "" && filter.GetType() != entity.GetType() {
+		return false
+	}
+	return len(filter.GetIds()) == 0 || slices.Contains(filter.GetIds(), entity.GetId())
+}
+
+func (s *fakeDataServer) ReadRelationships(ctx context.Context, req *permify_payload.RelationshipReadRequest) (*permify_payload.RelationshipReadResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	resp := &permify_payload.RelationshipReadResponse{}
+	filter := req.GetFilter()
+	for _, tuple := range s.tuples[req.TenantId] {
+		subject := tuple.GetSubject()
+		switch {
+		case !fakeEntityMatches(filter.GetEntity(), tuple.GetEntity()):
+		case filter.GetRelation() != "" && filter.GetRelation() != tuple.GetRelation():
+		case filter.GetSubject().GetType() != "" && filter.GetSubject().GetType() != subject.GetType():
+		case filter.GetSubject().GetRelation() != "" && filter.GetSubject().GetRelation() != subject.GetRelation():
+		case len(resp.Tuples) == int(req.PageSize):
+		default:
+			resp.Tuples = append(resp.Tuples, tuple)
+		}
+	}
+	return resp, nil
+}
+
+func (s *fakeDataServer) ReadAttributes(ctx context.Context, req *permify_payload.AttributeReadRequest) (*permify_payload.AttributeReadResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	resp := &permify_payload.AttributeReadResponse{}
+	filter := req.GetFilter()
+	for _, attribute := range s.attributes[req.TenantId] {
+		switch {
+		case !fakeEntityMatches(filter.GetEntity(), attribute.GetEntity()):
+		case len(filter.GetAttributes()) > 0 && !slices.Contains(filter.GetAttributes(), attribute.GetAttribute()):
+		case len(resp.Attributes) == int(req.PageSize):
+		default:
+			resp.Attributes = append(resp.Attributes, attribute)
+		}
+	}
+	return resp, nil
+}
+
+// fakeWatchServer answers every watch with a single empty change set.
+type fakeWatchServer struct {
+	basev1grpc.UnimplementedWatchServer
+}
+
+func (s *fakeWatchServer) Watch(req *permify_payload.WatchRequest, stream grpc.ServerStreamingServer[permify_payload.WatchResponse]) error {
+	return stream.Send(&permify_payload.WatchResponse{})
+}
+
+// requireAuthorization rejects unary and streaming calls whose authorization
+// metadata is not exactly want.
+func requireAuthorization(want string) []grpc.ServerOption {
+	check := func(ctx context.Context) error {
+		md, _ := metadata.FromIncomingContext(ctx)
+		if got := md.Get("authorization"); len(got) != 1 || got[0] != want {
+			return status.Error(codes.Unauthenticated, "missing or invalid authorization")
+		}
+		return nil
+	}
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			if err := check(ctx); err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		}),
+		grpc.ChainStreamInterceptor(func(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			if err := check(stream.Context()); err != nil {
+				return err
+			}
+			return handler(srv, stream)
+		}),
+	}
+}
+
+// testPKI holds a throwaway CA with a server and a client certificate signed by
+// it.  The server certificate is only valid for testServerName.
+type testPKI struct {
+	caPEM         []byte
+	serverCertPEM []byte
+	serverKeyPEM  []byte
+	clientCertPEM []byte
+	clientKeyPEM  []byte
+}
+
+const testServerName = "permify.test"
+
+func newTestPKI(t *testing.T) *testPKI {
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "permify test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	require.NoError(t, err)
+	ca, err := x509.ParseCertificate(caDER)
+	require.NoError(t, err)
+
+	issue := func(serial int64, template *x509.Certificate) ([]byte, []byte) {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+		template.SerialNumber = big.NewInt(serial)
+		template.NotBefore = time.Now().Add(-time.Hour)
+		template.NotAfter = time.Now().Add(time.Hour)
+		template.KeyUsage = x509.KeyUsageDigitalSignature
+		der, err := x509.CreateCertificate(rand.Reader, template, ca, &key.PublicKey, caKey)
+		require.NoError(t, err)
+		keyDER, err := x509.MarshalECPrivateKey(key)
+		require.NoError(t, err)
+		return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+			pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	}
+
+	pki := &testPKI{
+		caPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDER}),
+	}
+	pki.serverCertPEM, pki.serverKeyPEM = issue(2, &x509.Certificate{
+		Subject:     pkix.Name{CommonName: testServerName},
+		DNSNames:    []string{testServerName},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	})
+	pki.clientCertPEM, pki.clientKeyPEM = issue(3, &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "terraform"},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	})
+	return pki
+}
+
+// serverConfig returns a TLS configuration for the fake server.  When
+// requireClientCert is set the server only accepts clients presenting a
+// certificate signed by the test CA.
+func (p *testPKI) serverConfig(t *testing.T, requireClientCert bool) *tls.Config {
+	cert, err := tls.X509KeyPair(p.serverCertPEM, p.serverKeyPEM)
+	require.NoError(t, err)
+	config := &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: []tls.Certificate{cert},
+	}
+	if requireClientCert {
+		pool := x509.NewCertPool()
+		require.True(t, pool.AppendCertsFromPEM(p.caPEM))
+		config.ClientCAs = pool
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return config
+}
+
+// flakyMethod fails the first failures calls to method with code.  When lost is
+// set the call is handled first and only its response is replaced, as if the
+// connection dropped after the server committed the change.  When hang is set
+// the failing calls never answer, and end when the client gives up on them.
+type flakyMethod struct {
+	method   string
+	failures int
+	code     codes.Code
+	lost     bool
+	hang     bool
+
+	mu    sync.Mutex
+	calls int
+}
+
+func (f *flakyMethod) serverOption() grpc.ServerOption {
+	return grpc.ChainUnaryInterceptor(func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if info.FullMethod != f.method {
+			return handler(ctx, req)
+		}
+
+		f.mu.Lock()
+		f.calls++
+		fail := f.calls <= f.failures
+		f.mu.Unlock()
+
+		if !fail {
+			return handler(ctx, req)
+		}
+		if f.hang {
+			<-ctx.Done()
+			return nil, status.FromContextError(ctx.Err()).Err()
+		}
+		if f.lost {
+			if _, err := handler(ctx, req); err != nil {
+				return nil, err
+			}
+		}
+		return nil, status.Errorf(f.code, "injected failure of %s", f.method)
+	})
+}
+
+// callCount returns the number of calls made to the method, failed or not.
+func (f *flakyMethod) callCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls
+}
@@ -2,6 +2,7 @@ package provider
 
 import (
 	permify_payload "buf.build/gen/go/permifyco/permify/protocolbuffers/go/base/v1"
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 )
 
@@ -19,9 +20,10 @@ type BundleModel struct {
 }
 
 type BundlesModel struct {
-	ID       types.String  `tfsdk:"id"`
-	TenantID types.String  `tfsdk:"tenant_id"`
-	Bundles  []BundleModel `tfsdk:"bundles"`
+	ID       types.String   `tfsdk:"id"`
+	TenantID types.String   `tfsdk:"tenant_id"`
+	Bundles  []BundleModel  `tfsdk:"bundles"`
+	Timeouts timeouts.Value `tfsdk:"timeouts"`
 }
 
 func (o OperationModel) toOperation() *permify_payload.Operation {
@@ -1,10 +1,425 @@
 package provider
 
-import "github.com/hashicorp/terraform-plugin-framework/types"
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	permify_payload "buf.build/gen/go/permifyco/permify/protocolbuffers/go/base/v1"
+	permify_grpc "github.com/Permify/permify-go/grpc"
+	"github.com/google/cel-go/cel"
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
 
 type SchemaModel struct {
-	ID            types.String `tfsdk:"id"`
-	TenantID      types.String `tfsdk:"tenant_id"`
-	Schema        types.String `tfsdk:"schema"`
-	SchemaVersion types.String `tfsdk:"schema_version"`
+	ID                    types.String      `tfsdk:"id"`
+	TenantID              types.String      `tfsdk:"tenant_id"`
+	Schema                SchemaStringValue `tfsdk:"schema"`
+	SourceVersion         types.String      `tfsdk:"source_version"`
+	SchemaVersion         types.String      `tfsdk:"schema_version"`
+	PreviousSchemaVersion types.String      `tfsdk:"previous_schema_version"`
+	VersionHistory        types.List        `tfsdk:"version_history"`
+	DestroyBehavior       types.String      `tfsdk:"destroy_behavior"`
+	Force                 types.Bool        `tfsdk:"force"`
+	AllowOrphanedData     types.Bool        `tfsdk:"allow_orphaned_data"`
+	WaitForPropagation    types.String      `tfsdk:"wait_for_propagation"`
+	Timeouts              timeouts.Value    `tfsdk:"timeouts"`
+}
+
+// SchemaDataSourceModel is a schema version read by the permify_schema data
+// source, with its declarations listed for modules to look up.
+type SchemaDataSourceModel struct {
+	TenantID      types.String        `tfsdk:"tenant_id"`
+	SchemaVersion types.String        `tfsdk:"schema_version"`
+	Schema        types.String        `tfsdk:"schema"`
+	Entities      []SchemaEntityModel `tfsdk:"entities"`
+}
+
+type SchemaEntityModel struct {
+	Name        string                  `tfsdk:"name"`
+	Relations   []SchemaRelationModel   `tfsdk:"relations"`
+	Attributes  []SchemaAttributeModel  `tfsdk:"attributes"`
+	Permissions []SchemaPermissionModel `tfsdk:"permissions"`
+}
+
+type SchemaRelationModel struct {
+	Name  string   `tfsdk:"name"`
+	Types []string `tfsdk:"types"`
+}
+
+type SchemaAttributeModel struct {
+	Name string `tfsdk:"name"`
+	Type string `tfsdk:"type"`
+}
+
+type SchemaPermissionModel struct {
+	Name       string `tfsdk:"name"`
+	Expression string `tfsdk:"expression"`
+}
+
+// fromSchema lists the declarations of a decompiled schema.  Lists are empty
+// rather than null, so that modules can iterate over them unconditionally.
+func (m *SchemaDataSourceModel) fromSchema(schema *dslSchema) {
+	m.Schema = types.StringValue(schema.String())
+	m.Entities = []SchemaEntityModel{}
+	for _, entity := range schema.Entities {
+		model := SchemaEntityModel{
+			Name:        entity.Name,
+			Relations:   []SchemaRelationModel{},
+			Attributes:  []SchemaAttributeModel{},
+			Permissions: []SchemaPermissionModel{},
+		}
+		for _, relation := range entity.Relations {
+			relationModel := SchemaRelationModel{Name: relation.Name, Types: []string{}}
+			for _, relationType := range relation.Types {
+				relationModel.Types = append(relationModel.Types, strings.TrimPrefix(relationType.String(), "@"))
+			}
+			model.Relations = append(model.Relations, relationModel)
+		}
+		for _, attribute := range entity.Attributes {
+			model.Attributes = append(model.Attributes, SchemaAttributeModel{Name: attribute.Name, Type: attribute.Type})
+		}
+		for _, permission := range entity.Permissions {
+			model.Permissions = append(model.Permissions, SchemaPermissionModel{Name: permission.Name, Expression: permission.Expr.render(false)})
+		}
+		m.Entities = append(m.Entities, model)
+	}
+}
+
+// SchemaVersionsDataSourceModel is the history of a tenant's schema, newest
+// version first.
+type SchemaVersionsDataSourceModel struct {
+	TenantID types.String         `tfsdk:"tenant_id"`
+	Limit    types.Int64          `tfsdk:"limit"`
+	Since    types.String         `tfsdk:"since"`
+	Head     types.String         `tfsdk:"head"`
+	Versions []SchemaVersionModel `tfsdk:"versions"`
+}
+
+type SchemaVersionModel struct {
+	Version   string `tfsdk:"version"`
+	CreatedAt string `tfsdk:"created_at"`
+}
+
+// listSchemaVersions pages through the schema versions of the tenant, newest
+// first, and returns the head with at most limit versions created at or after
+// since.  A limit of zero lists every version, as does a zero since.
+func listSchemaVersions(ctx context.Context, client *permify_grpc.Client, tenantID string, limit int, since time.Time) (string, []*permify_payload.SchemaList, error) {
+	var head string
+	var versions []*permify_payload.SchemaList
+	token := ""
+	firstRun := true
+
+	for token != "" || firstRun {
+		result, err := client.Schema.List(ctx, &permify_payload.SchemaListRequest{
+			TenantId:        tenantID,
+			PageSize:        100,
+			ContinuousToken: token,
+		})
+		if status.Code(err) == codes.NotFound {
+			return "", nil, nil
+		}
+		if err != nil {
+			return "", nil, err
+		}
+		if firstRun {
+			head = result.Head
+		}
+		for _, version := range result.Schemas {
+			if !since.IsZero() {
+				createdAt, err := time.Parse(time.RFC3339, version.CreatedAt)
+				if err != nil {
+					return "", nil, fmt.Errorf("schema version %s has an unexpected creation time %q: %w", version.Version, version.CreatedAt, err)
+				}
+				// Versions come newest first, so the rest are older still.
+				if createdAt.Before(since) {
+					return head, versions, nil
+				}
+			}
+			versions = append(versions, version)
+			if limit > 0 && len(versions) == limit {
+				return head, versions, nil
+			}
+		}
+		firstRun = false
+		token = result.ContinuousToken
+	}
+	return head, versions, nil
+}
+
+// What destroying a permify_schema does to the schema in Permify, which has
+// no way of deleting one.
+const (
+	destroyBehaviorRetain = "retain"
+	destroyBehaviorClear  = "clear"
+	destroyBehaviorFail   = "fail"
+)
+
+// clearedSchema is written to a tenant by destroy_behavior = "clear".  It
+// declares a single entity without relations, so it grants nothing.
+const clearedSchema = "entity user {}\n"
+
+// recordVersion notes that the schema is now at the given version, after a
+// write made by this resource when written is set.
+func (m *SchemaModel) recordVersion(ctx context.Context, version string, written bool) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if !m.SchemaVersion.IsNull() && !m.SchemaVersion.IsUnknown() && m.SchemaVersion.ValueString() != version {
+		m.PreviousSchemaVersion = m.SchemaVersion
+	} else if m.PreviousSchemaVersion.IsUnknown() {
+		m.PreviousSchemaVersion = types.StringNull()
+	}
+	m.SchemaVersion = types.StringValue(version)
+
+	history := []string{}
+	if !m.VersionHistory.IsNull() && !m.VersionHistory.IsUnknown() {
+		diags.Append(m.VersionHistory.ElementsAs(ctx, &history, false)...)
+	}
+	if written {
+		history = append(history, version)
+	}
+	var listDiags diag.Diagnostics
+	m.VersionHistory, listDiags = types.ListValueFrom(ctx, types.StringType, history)
+	diags.Append(listDiags...)
+
+	return diags
+}
+
+// drifted reports whether the tenant's schema has moved on from the version
+// this resource wrote last, as noticed by Read.
+func (m *SchemaModel) drifted(ctx context.Context) bool {
+	var history []string
+	if m.VersionHistory.IsNull() || m.VersionHistory.IsUnknown() || m.VersionHistory.ElementsAs(ctx, &history, false).HasError() {
+		return false
+	}
+	return len(history) > 0 && history[len(history)-1] != m.SchemaVersion.ValueString()
+}
+
+// readSchemaVersion reads and decompiles a version of the tenant's schema.
+func readSchemaVersion(ctx context.Context, client *permify_grpc.Client, tenantID string, version string) (*dslSchema, error) {
+	result, err := client.Schema.Read(ctx, &permify_payload.SchemaReadRequest{
+		TenantId: tenantID,
+		Metadata: &permify_payload.SchemaReadRequestMetadata{SchemaVersion: version},
+	})
+	if err != nil {
+		return nil, err
+	}
+	schema, err := FromSchemaDefinition(result.Schema)
+	if err != nil {
+		return nil, fmt.Errorf("schema version %s cannot be decompiled: %w", version, err)
+	}
+	return schema, nil
+}
+
+// defaultPropagationInterval is how often a written schema version is read
+// back while waiting for it to propagate.
+const defaultPropagationInterval = 250 * time.Millisecond
+
+// propagationTimeout returns how long to wait for a written schema version to
+// become readable, which is zero when wait_for_propagation is not set.
+func (m *SchemaModel) propagationTimeout() (time.Duration, error) {
+	if m.WaitForPropagation.IsNull() || m.WaitForPropagation.IsUnknown() {
+		return 0, nil
+	}
+	timeout, err := time.ParseDuration(m.WaitForPropagation.ValueString())
+	if err != nil {
+		return 0, fmt.Errorf("invalid wait_for_propagation: %w", err)
+	}
+	if timeout <= 0 {
+		return 0, fmt.Errorf("wait_for_propagation must be positive, got %s", timeout)
+	}
+	return timeout, nil
+}
+
+// waitForSchemaVersion reads the schema version until a read succeeds, for
+// clusters whose nodes see new versions at different times.  It returns the
+// last error seen when the version is not readable within the timeout.
+func waitForSchemaVersion(ctx context.Context, client *permify_grpc.Client, tenantID string, version string, timeout time.Duration, interval time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	var lastErr error
+	for {
+		_, err := client.Schema.Read(ctx, &permify_payload.SchemaReadRequest{
+			TenantId: tenantID,
+			Metadata: &permify_payload.SchemaReadRequestMetadata{SchemaVersion: version},
+		})
+		if err == nil {
+			return nil
+		}
+		// A read cut short by the end of the wait says less than the one
+		// before it.
+		if lastErr == nil || ctx.Err() == nil {
+			lastErr = err
+		}
+		tflog.Debug(ctx, "Waiting for Permify Schema version to propagate", map[string]any{"version": version, "error": err.Error()})
+
+		select {
+		case <-ctx.Done():
+			return lastErr
+		case <-ticker.C:
+		}
+	}
+}
+
+// attributeTypes maps the attribute types of compiled schemas to the DSL.
+var attributeTypes = map[permify_payload.AttributeType]string{
+	permify_payload.AttributeType_ATTRIBUTE_TYPE_BOOLEAN:       "boolean",
+	permify_payload.AttributeType_ATTRIBUTE_TYPE_BOOLEAN_ARRAY: "boolean[]",
+	permify_payload.AttributeType_ATTRIBUTE_TYPE_STRING:        "string",
+	permify_payload.AttributeType_ATTRIBUTE_TYPE_STRING_ARRAY:  "string[]",
+	permify_payload.AttributeType_ATTRIBUTE_TYPE_INTEGER:       "integer",
+	permify_payload.AttributeType_ATTRIBUTE_TYPE_INTEGER_ARRAY: "integer[]",
+	permify_payload.AttributeType_ATTRIBUTE_TYPE_DOUBLE:        "double",
+	permify_payload.AttributeType_ATTRIBUTE_TYPE_DOUBLE_ARRAY:  "double[]",
+}
+
+var rewriteOperators = map[permify_payload.Rewrite_Operation]string{
+	permify_payload.Rewrite_OPERATION_UNION:        "or",
+	permify_payload.Rewrite_OPERATION_INTERSECTION: "and",
+	permify_payload.Rewrite_OPERATION_EXCLUSION:    "not",
+}
+
+// FromSchemaDefinition decompiles a schema read from Permify.  The compiled
+// form does not keep the order of declarations, so entities, rules and their
+// members come out sorted by name.
+func FromSchemaDefinition(definition *permify_payload.SchemaDefinition) (*dslSchema, error) {
+	schema := &dslSchema{}
+
+	for _, name := range sortedKeys(definition.GetEntityDefinitions()) {
+		entity, err := fromEntityDefinition(definition.GetEntityDefinitions()[name])
+		if err != nil {
+			return nil, fmt.Errorf("entity %s: %w", name, err)
+		}
+		schema.Entities = append(schema.Entities, entity)
+	}
+	for _, name := range sortedKeys(definition.GetRuleDefinitions()) {
+		rule, err := fromRuleDefinition(definition.GetRuleDefinitions()[name])
+		if err != nil {
+			return nil, fmt.Errorf("rule %s: %w", name, err)
+		}
+		schema.Rules = append(schema.Rules, rule)
+	}
+
+	return schema, nil
+}
+
+func fromEntityDefinition(definition *permify_payload.EntityDefinition) (*dslEntity, error) {
+	entity := &dslEntity{Name: definition.GetName()}
+
+	for _, name := range sortedKeys(definition.GetRelations()) {
+		relation := &dslRelation{Name: name}
+		for _, reference := range definition.GetRelations()[name].GetRelationReferences() {
+			relation.Types = append(relation.Types, dslRelationType{Entity: reference.GetType(), Relation: reference.GetRelation()})
+		}
+		entity.Relations = append(entity.Relations, relation)
+	}
+	for _, name := range sortedKeys(definition.GetAttributes()) {
+		attributeType, ok := attributeTypes[definition.GetAttributes()[name].GetType()]
+		if !ok {
+			return nil, fmt.Errorf("attribute %s has unsupported type %s", name, definition.GetAttributes()[name].GetType())
+		}
+		entity.Attributes = append(entity.Attributes, &dslAttribute{Name: name, Type: attributeType})
+	}
+	for _, name := range sortedKeys(definition.GetPermissions()) {
+		expr, err := fromChild(definition.GetPermissions()[name].GetChild())
+		if err != nil {
+			return nil, fmt.Errorf("permission %s: %w", name, err)
+		}
+		entity.Permissions = append(entity.Permissions, &dslPermission{Name: name, Expr: expr})
+	}
+
+	return entity, nil
+}
+
+func fromChild(child *permify_payload.Child) (dslExpr, error) {
+	if rewrite := child.GetRewrite(); rewrite != nil {
+		operator, ok := rewriteOperators[rewrite.GetRewriteOperation()]
+		if !ok {
+			return nil, fmt.Errorf("unsupported operation %s", rewrite.GetRewriteOperation())
+		}
+		expr := dslRewrite{Operator: operator}
+		for _, grandchild := range rewrite.GetChildren() {
+			converted, err := fromChild(grandchild)
+			if err != nil {
+				return nil, err
+			}
+			expr.Children = append(expr.Children, converted)
+		}
+		return flattenRewrite(expr), nil
+	}
+
+	leaf := child.GetLeaf()
+	switch {
+	case leaf.GetComputedUserSet() != nil:
+		return dslIdent{Parts: []string{leaf.GetComputedUserSet().GetRelation()}}, nil
+	case leaf.GetTupleToUserSet() != nil:
+		tupleToUserSet := leaf.GetTupleToUserSet()
+		return dslIdent{Parts: []string{tupleToUserSet.GetTupleSet().GetRelation(), tupleToUserSet.GetComputed().GetRelation()}}, nil
+	case leaf.GetComputedAttribute() != nil:
+		return dslIdent{Parts: []string{leaf.GetComputedAttribute().GetName()}}, nil
+	case leaf.GetCall() != nil:
+		call := dslCall{Rule: leaf.GetCall().GetRuleName()}
+		for _, argument := range leaf.GetCall().GetArguments() {
+			call.Arguments = append(call.Arguments, argument.GetComputedAttribute().GetName())
+		}
+		return call, nil
+	}
+	return nil, fmt.Errorf("empty permission node")
+}
+
+// flattenRewrite merges children that apply the same associative operator,
+// so that `a or b or c` reads the same however it was nested.
+func flattenRewrite(expr dslRewrite) dslRewrite {
+	if expr.Operator == "not" {
+		return expr
+	}
+	var children []dslExpr
+	for _, child := range expr.Children {
+		if rewrite, ok := child.(dslRewrite); ok && rewrite.Operator == expr.Operator {
+			children = append(children, rewrite.Children...)
+		} else {
+			children = append(children, child)
+		}
+	}
+	expr.Children = children
+	return expr
+}
+
+func fromRuleDefinition(definition *permify_payload.RuleDefinition) (*dslRule, error) {
+	rule := &dslRule{Name: definition.GetName()}
+
+	for _, name := range sortedKeys(definition.GetArguments()) {
+		argumentType, ok := attributeTypes[definition.GetArguments()[name]]
+		if !ok {
+			return nil, fmt.Errorf("argument %s has unsupported type %s", name, definition.GetArguments()[name])
+		}
+		rule.Arguments = append(rule.Arguments, &dslAttribute{Name: name, Type: argumentType})
+	}
+
+	body, err := cel.AstToString(cel.CheckedExprToAst(definition.GetExpression()))
+	if err != nil {
+		return nil, fmt.Errorf("decompiling expression: %w", err)
+	}
+	rule.Body = body
+
+	return rule, nil
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
 }
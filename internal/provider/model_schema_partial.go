@@ -0,0 +1,187 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	permify_payload "buf.build/gen/go/permifyco/permify/protocolbuffers/go/base/v1"
+	permify_grpc "github.com/Permify/permify-go/grpc"
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type SchemaPartialModel struct {
+	ID            types.String      `tfsdk:"id"`
+	TenantID      types.String      `tfsdk:"tenant_id"`
+	Schema        SchemaStringValue `tfsdk:"schema"`
+	SchemaVersion types.String      `tfsdk:"schema_version"`
+	Timeouts      timeouts.Value    `tfsdk:"timeouts"`
+}
+
+// parsePartialSchema parses the entity blocks of a permify_schema_partial,
+// which may be empty when there is nothing to parse.
+func parsePartialSchema(value SchemaStringValue) (*dslSchema, error) {
+	if value.IsNull() || value.IsUnknown() {
+		return &dslSchema{}, nil
+	}
+	schema, err := parseSchema(value.ValueString())
+	if err != nil {
+		return nil, err
+	}
+	if len(schema.Rules) > 0 {
+		return nil, fmt.Errorf("rule %s: partial schemas can only hold entities, as Permify cannot write rules partially", schema.Rules[0].Name)
+	}
+	schema.sortDeclarations()
+	return schema, nil
+}
+
+// statements returns the relations, attributes and permissions of the entity
+// by name, written as partial writes take them.
+func (e *dslEntity) statements() map[string]string {
+	statements := map[string]string{}
+	for _, relation := range e.Relations {
+		statements[relation.Name] = relation.String()
+	}
+	for _, attribute := range e.Attributes {
+		statements[attribute.Name] = fmt.Sprintf("attribute %s %s", attribute.Name, attribute.Type)
+	}
+	for _, permission := range e.Permissions {
+		statements[permission.Name] = fmt.Sprintf("permission %s = %s", permission.Name, permission.Expr.render(false))
+	}
+	return statements
+}
+
+func (s *dslSchema) entity(name string) *dslEntity {
+	for _, entity := range s.Entities {
+		if entity.Name == name {
+			return entity
+		}
+	}
+	return nil
+}
+
+// schemaPartialChange is the partial write that takes a tenant's schema from
+// the entity members in prior to the ones in planned.
+type schemaPartialChange struct {
+	partials  map[string]*permify_payload.Partials
+	conflicts []string
+	missing   []string
+}
+
+// diffPartialSchemas works out the partial write from the members managed
+// before, prior, to the members managed now, planned, given the tenant's
+// current schema.  A member that is not managed before but exists in Permify
+// belongs to someone else, even when it is declared the same way, and is
+// reported as a conflict, as are entities missing from Permify.
+func diffPartialSchemas(prior *dslSchema, planned *dslSchema, remote *dslSchema) schemaPartialChange {
+	change := schemaPartialChange{partials: map[string]*permify_payload.Partials{}}
+	partial := func(entity string) *permify_payload.Partials {
+		if change.partials[entity] == nil {
+			change.partials[entity] = &permify_payload.Partials{}
+		}
+		return change.partials[entity]
+	}
+
+	names := map[string]bool{}
+	for _, entity := range prior.Entities {
+		names[entity.Name] = true
+	}
+	for _, entity := range planned.Entities {
+		names[entity.Name] = true
+	}
+
+	for _, name := range sortedKeys(names) {
+		var owned, wanted, current map[string]string
+		if entity := prior.entity(name); entity != nil {
+			owned = entity.statements()
+		}
+		if entity := planned.entity(name); entity != nil {
+			wanted = entity.statements()
+		}
+		remoteEntity := remote.entity(name)
+		if remoteEntity == nil {
+			if wanted != nil {
+				change.missing = append(change.missing, name)
+			}
+			continue
+		}
+		current = remoteEntity.statements()
+
+		for _, member := range sortedKeys(wanted) {
+			statement := wanted[member]
+			existing, exists := current[member]
+			_, isOwned := owned[member]
+			switch {
+			case !exists:
+				partial(name).Write = append(partial(name).Write, statement)
+			case !isOwned:
+				change.conflicts = append(change.conflicts, fmt.Sprintf("%s of entity %s is already declared as `%s`", member, name, existing))
+			case existing != statement:
+				partial(name).Update = append(partial(name).Update, statement)
+			}
+		}
+		for _, member := range sortedKeys(owned) {
+			if _, keep := wanted[member]; keep {
+				continue
+			}
+			if _, exists := current[member]; exists {
+				partial(name).Delete = append(partial(name).Delete, member)
+			}
+		}
+	}
+
+	return change
+}
+
+// readPartialSchema returns the members of the managed entities as they are
+// in Permify, keeping only the members that prior manages.
+func readPartialSchema(prior *dslSchema, remote *dslSchema) *dslSchema {
+	current := &dslSchema{}
+	for _, entity := range prior.Entities {
+		owned := entity.statements()
+		read := &dslEntity{Name: entity.Name}
+		if remoteEntity := remote.entity(entity.Name); remoteEntity != nil {
+			for _, relation := range remoteEntity.Relations {
+				if _, ok := owned[relation.Name]; ok {
+					read.Relations = append(read.Relations, relation)
+				}
+			}
+			for _, attribute := range remoteEntity.Attributes {
+				if _, ok := owned[attribute.Name]; ok {
+					read.Attributes = append(read.Attributes, attribute)
+				}
+			}
+			for _, permission := range remoteEntity.Permissions {
+				if _, ok := owned[permission.Name]; ok {
+					read.Permissions = append(read.Permissions, permission)
+				}
+			}
+		}
+		current.Entities = append(current.Entities, read)
+	}
+	return current
+}
+
+// readSchemaHead returns the latest version of the tenant's schema, with its
+// declarations sorted, or an empty version when the tenant has no schema.
+func readSchemaHead(ctx context.Context, client *permify_grpc.Client, tenantID string) (string, *dslSchema, error) {
+	list, err := client.Schema.List(ctx, &permify_payload.SchemaListRequest{
+		TenantId: tenantID,
+		PageSize: 1,
+	})
+	if status.Code(err) == codes.NotFound || (err == nil && list.Head == "") {
+		return "", nil, nil
+	}
+	if err != nil {
+		return "", nil, err
+	}
+
+	schema, err := readSchemaVersion(ctx, client, tenantID, list.Head)
+	if err != nil {
+		return "", nil, err
+	}
+	schema.sortDeclarations()
+	return list.Head, schema, nil
+}
@@ -0,0 +1,176 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	permify_payload "buf.build/gen/go/permifyco/permify/protocolbuffers/go/base/v1"
+	"github.com/google/cel-go/cel"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/stretchr/testify/require"
+)
+
+// testCompiledSchema is testCompiledSchemaDefinition as Permify compiles it.
+const testCompiledSchema = `entity organization {
+    relation admin @user
+    relation member @user @organization#admin
+    relation viewer @user
+
+    attribute credit integer
+    attribute tags string[]
+
+    permission edit = admin and check_credit(credit)
+    permission view = admin or member or viewer
+}
+
+entity repository {
+    relation owner @user
+    relation parent @organization
+
+    permission delete = (parent.admin or owner) not parent.member
+}
+
+entity user {}
+
+rule check_credit(credit integer) {
+    credit > 5000
+}
+`
+
+func testCompiledSchemaDefinition(t *testing.T) *permify_payload.SchemaDefinition {
+	env, err := cel.NewEnv(cel.Variable("credit", cel.IntType))
+	require.NoError(t, err)
+	ast, issues := env.Compile("credit > 5000")
+	require.NoError(t, issues.Err())
+	expression, err := cel.AstToCheckedExpr(ast)
+	require.NoError(t, err)
+
+	leaf := func(leaf *permify_payload.Leaf) *permify_payload.Child {
+		return &permify_payload.Child{Type: &permify_payload.Child_Leaf{Leaf: leaf}}
+	}
+	computed := func(relation string) *permify_payload.Child {
+		return leaf(&permify_payload.Leaf{Type: &permify_payload.Leaf_ComputedUserSet{
+			ComputedUserSet: &permify_payload.ComputedUserSet{Relation: relation},
+		}})
+	}
+	tupleToUserSet := func(tupleSet string, relation string) *permify_payload.Child {
+		return leaf(&permify_payload.Leaf{Type: &permify_payload.Leaf_TupleToUserSet{
+			TupleToUserSet: &permify_payload.TupleToUserSet{
+				TupleSet: &permify_payload.TupleSet{Relation: tupleSet},
+				Computed: &permify_payload.ComputedUserSet{Relation: relation},
+			},
+		}})
+	}
+	rewrite := func(operation permify_payload.Rewrite_Operation, children ...*permify_payload.Child) *permify_payload.Child {
+		return &permify_payload.Child{Type: &permify_payload.Child_Rewrite{Rewrite: &permify_payload.Rewrite{
+			RewriteOperation: operation,
+			Children:         children,
+		}}}
+	}
+	call := leaf(&permify_payload.Leaf{Type: &permify_payload.Leaf_Call{Call: &permify_payload.Call{
+		RuleName: "check_credit",
+		Arguments: []*permify_payload.Argument{{
+			Type: &permify_payload.Argument_ComputedAttribute{ComputedAttribute: &permify_payload.ComputedAttribute{Name: "credit"}},
+		}},
+	}}})
+
+	return &permify_payload.SchemaDefinition{
+		EntityDefinitions: map[string]*permify_payload.EntityDefinition{
+			"user": {Name: "user"},
+			"organization": {
+				Name: "organization",
+				Relations: map[string]*permify_payload.RelationDefinition{
+					"admin": {Name: "admin", RelationReferences: []*permify_payload.RelationReference{{Type: "user"}}},
+					"member": {Name: "member", RelationReferences: []*permify_payload.RelationReference{
+						{Type: "user"},
+						{Type: "organization", Relation: "admin"},
+					}},
+					"viewer": {Name: "viewer", RelationReferences: []*permify_payload.RelationReference{{Type: "user"}}},
+				},
+				Attributes: map[string]*permify_payload.AttributeDefinition{
+					"credit": {Name: "credit", Type: permify_payload.AttributeType_ATTRIBUTE_TYPE_INTEGER},
+					"tags":   {Name: "tags", Type: permify_payload.AttributeType_ATTRIBUTE_TYPE_STRING_ARRAY},
+				},
+				Permissions: map[string]*permify_payload.PermissionDefinition{
+					"view": {Name: "view", Child: rewrite(permify_payload.Rewrite_OPERATION_UNION,
+						rewrite(permify_payload.Rewrite_OPERATION_UNION, computed("admin"), computed("member")),
+						computed("viewer"),
+					)},
+					"edit": {Name: "edit", Child: rewrite(permify_payload.Rewrite_OPERATION_INTERSECTION, computed("admin"), call)},
+				},
+			},
+			"repository": {
+				Name: "repository",
+				Relations: map[string]*permify_payload.RelationDefinition{
+					"parent": {Name: "parent", RelationReferences: []*permify_payload.RelationReference{{Type: "organization"}}},
+					"owner":  {Name: "owner", RelationReferences: []*permify_payload.RelationReference{{Type: "user"}}},
+				},
+				Permissions: map[string]*permify_payload.PermissionDefinition{
+					"delete": {Name: "delete", Child: rewrite(permify_payload.Rewrite_OPERATION_EXCLUSION,
+						rewrite(permify_payload.Rewrite_OPERATION_UNION, tupleToUserSet("parent", "admin"), computed("owner")),
+						tupleToUserSet("parent", "member"),
+					)},
+				},
+			},
+		},
+		RuleDefinitions: map[string]*permify_payload.RuleDefinition{
+			"check_credit": {
+				Name:       "check_credit",
+				Arguments:  map[string]permify_payload.AttributeType{"credit": permify_payload.AttributeType_ATTRIBUTE_TYPE_INTEGER},
+				Expression: expression,
+			},
+		},
+	}
+}
+
+func TestFromSchemaDefinition(t *testing.T) {
+	schema, err := FromSchemaDefinition(testCompiledSchemaDefinition(t))
+	require.NoError(t, err)
+	require.Equal(t, testCompiledSchema, schema.String())
+}
+
+func TestFromSchemaDefinitionUnsupported(t *testing.T) {
+	_, err := FromSchemaDefinition(&permify_payload.SchemaDefinition{
+		EntityDefinitions: map[string]*permify_payload.EntityDefinition{
+			"user": {
+				Name: "user",
+				Attributes: map[string]*permify_payload.AttributeDefinition{
+					"age": {Name: "age"},
+				},
+			},
+		},
+	})
+	require.ErrorContains(t, err, "entity user: attribute age has unsupported type")
+}
+
+func TestSchemaModelRecordVersion(t *testing.T) {
+	ctx := context.Background()
+	model := SchemaModel{
+		SchemaVersion:         types.StringUnknown(),
+		PreviousSchemaVersion: types.StringUnknown(),
+		VersionHistory:        types.ListUnknown(types.StringType),
+	}
+	history := func() []string {
+		var versions []string
+		require.False(t, model.VersionHistory.ElementsAs(ctx, &versions, false).HasError())
+		return versions
+	}
+
+	// Created
+	require.False(t, model.recordVersion(ctx, "v1", true).HasError())
+	require.Equal(t, "v1", model.SchemaVersion.ValueString())
+	require.True(t, model.PreviousSchemaVersion.IsNull())
+	require.Equal(t, []string{"v1"}, history())
+
+	// Updated
+	require.False(t, model.recordVersion(ctx, "v2", true).HasError())
+	require.Equal(t, "v2", model.SchemaVersion.ValueString())
+	require.Equal(t, "v1", model.PreviousSchemaVersion.ValueString())
+	require.Equal(t, []string{"v1", "v2"}, history())
+
+	// Changed outside of Terraform
+	require.False(t, model.recordVersion(ctx, "v3", false).HasError())
+	require.Equal(t, "v3", model.SchemaVersion.ValueString())
+	require.Equal(t, "v2", model.PreviousSchemaVersion.ValueString())
+	require.Equal(t, []string{"v1", "v2"}, history())
+}
@@ -4,6 +4,7 @@ import (
 	permify_payload "buf.build/gen/go/permifyco/permify/protocolbuffers/go/base/v1"
 	"context"
 	permify_grpc "github.com/Permify/permify-go/grpc"
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 )
 
@@ -13,6 +14,13 @@ type TenantModel struct {
 	CreatedAt types.String `tfsdk:"created_at"`
 }
 
+// TenantResourceModel adds the resource's timeouts to the attributes it shares
+// with the tenant data source.
+type TenantResourceModel struct {
+	TenantModel
+	Timeouts timeouts.Value `tfsdk:"timeouts"`
+}
+
 func findTenant(ctx context.Context, client *permify_grpc.Client, id string) (*permify_payload.Tenant, error) {
 	token := ""
 	firstRun := true
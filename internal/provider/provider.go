@@ -2,21 +2,37 @@ package provider
 
 import (
 	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
 
 	permify_grpc "github.com/Permify/permify-go/grpc"
+	"github.com/hashicorp/terraform-plugin-framework-validators/mapvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/provider"
 	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"golang.org/x/oauth2"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
-	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 )
 
 var _ provider.Provider = &permifyProvider{}
 var _ provider.ProviderWithFunctions = &permifyProvider{}
+var _ provider.ProviderWithValidateConfig = &permifyProvider{}
+var _ credentials.PerRPCCredentials = tokenCredentials{}
+var _ credentials.PerRPCCredentials = headerCredentials{}
 
 type permifyProvider struct {
 	// version is set to the provider version on release, "dev" when the
@@ -26,8 +42,17 @@ type permifyProvider struct {
 }
 
 type PermifyProviderModel struct {
-	Endpoint types.String `tfsdk:"endpoint"`
-	Token    types.String `tfsdk:"token"`
+	Endpoint     types.String       `tfsdk:"endpoint"`
+	Token        types.String       `tfsdk:"token"`
+	Profile      types.String       `tfsdk:"profile"`
+	ConfigFile   types.String       `tfsdk:"config_file"`
+	CallTimeout  types.String       `tfsdk:"call_timeout"`
+	Headers      types.Map          `tfsdk:"headers"`
+	TLS          *TLSModel          `tfsdk:"tls"`
+	OAuth2       *OAuth2Model       `tfsdk:"oauth2"`
+	Retry        *RetryModel        `tfsdk:"retry"`
+	WaitForReady *WaitForReadyModel `tfsdk:"wait_for_ready"`
+	SchemaLint   *SchemaLintModel   `tfsdk:"schema_lint"`
 }
 
 func (p *permifyProvider) Metadata(ctx context.Context, req provider.MetadataRequest, resp *provider.MetadataResponse) {
@@ -37,21 +62,111 @@ func (p *permifyProvider) Metadata(ctx context.Context, req provider.MetadataReq
 
 func (p *permifyProvider) Schema(ctx context.Context, req provider.SchemaRequest, resp *provider.SchemaResponse) {
 	resp.Schema = schema.Schema{
+		MarkdownDescription: "Settings that are not set in the provider block fall back to environment variables, and then to the " +
+			"selected `profile` of the Permify config file.  Profiles are read from `~/.permify/config.yaml` unless `config_file` " +
+			"says otherwise, and may set `endpoint`, `token` and the settings of the `tls` block:\n\n" +
+			"```yaml\nprofiles:\n  staging:\n    endpoint: permify.staging.example.com:3478\n    token: shared-key\n" +
+			"    tls:\n      ca_certificate_file: /etc/ssl/permify-ca.pem\n```\n\n" +
+			"The environment variables are `PERMIFY_ENDPOINT`, `PERMIFY_TOKEN`, `PERMIFY_PROFILE`, `PERMIFY_CONFIG_FILE`, `PERMIFY_TLS`, " +
+			"`PERMIFY_TLS_CA_CERTIFICATE_FILE`, `PERMIFY_TLS_CLIENT_CERTIFICATE_FILE`, `PERMIFY_TLS_CLIENT_KEY_FILE`, " +
+			"`PERMIFY_TLS_SERVER_NAME` and `PERMIFY_TLS_INSECURE_SKIP_VERIFY`.",
 		Attributes: map[string]schema.Attribute{
 			"endpoint": schema.StringAttribute{
-				MarkdownDescription: "gRPC endpoint for the Permify API.  Defaults to `localhost:3478`.",
-				Required:            true,
+				MarkdownDescription: "gRPC endpoint for the Permify API.  Can also be set with `PERMIFY_ENDPOINT`.  Defaults to `" + defaultEndpoint + "`.",
+				Optional:            true,
 			},
 			"token": schema.StringAttribute{
-				MarkdownDescription: "Bearer Token to authenticated to the Permify API.  Can be an OAuth2 token a Pre-Shared Key.",
+				MarkdownDescription: "Bearer Token to authenticated to the Permify API.  Can be an OAuth2 token a Pre-Shared Key.  Can also be set with `PERMIFY_TOKEN`.",
 				Optional:            true,
 				Sensitive:           true,
 			},
+			"profile": schema.StringAttribute{
+				MarkdownDescription: "Name of a profile in the Permify config file to read unset settings from.  Can also be set with `PERMIFY_PROFILE`.",
+				Optional:            true,
+			},
+			"config_file": schema.StringAttribute{
+				MarkdownDescription: "Path to the Permify config file holding the profiles.  Can also be set with `PERMIFY_CONFIG_FILE`.  Defaults to `~/.permify/config.yaml`.",
+				Optional:            true,
+			},
+			"call_timeout": schema.StringAttribute{
+				MarkdownDescription: "Deadline of every attempt of a call to the Permify API, as a Go duration such as `10s`.  Attempts that time out " +
+					"are retried like any other `DEADLINE_EXCEEDED` failure.  The overall time of an operation is limited by the resource's " +
+					"`timeouts` block instead.  Defaults to `" + defaultCallTimeout.String() + "`.",
+				Optional: true,
+			},
+			"headers": schema.MapAttribute{
+				MarkdownDescription: "Extra gRPC metadata sent with every call, for example for a gateway that routes on headers.  " +
+					"Names are sent in lower case.  `authorization` and `user-agent` are set by the provider and cannot be overridden.",
+				Optional:    true,
+				ElementType: types.StringType,
+				Validators: []validator.Map{
+					mapvalidator.KeysAre(
+						stringvalidator.RegexMatches(headerNamePattern, "must only contain letters, digits, '-', '_' and '.'"),
+						stringvalidator.NoneOfCaseInsensitive(reservedHeaders...),
+					),
+				},
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"tls":            tlsBlock(),
+			"oauth2":         oauth2Block(),
+			"retry":          retryBlock(),
+			"wait_for_ready": waitForReadyBlock(),
+			"schema_lint":    schemaLintBlock(),
 		},
 	}
 }
 
+// providerData is handed to every resource and data source by Configure.
+type providerData struct {
+	// client is nil when the provider could not be configured.
+	client *permify_grpc.Client
+	// configUnknown is set when the provider configuration depends on values
+	// that are only known after apply and Terraform cannot defer the resources
+	// that use it.  No client is created until the configuration is known.
+	configUnknown bool
+	// schemaLint holds the rules every permify_schema is checked against.
+	schemaLint schemaLintRules
+}
+
+// ValidateConfig checks the timeouts that are already known, so that
+// `terraform validate` reports them on their attribute.
+func (p *permifyProvider) ValidateConfig(ctx context.Context, req provider.ValidateConfigRequest, resp *provider.ValidateConfigResponse) {
+	var callTimeoutValue types.String
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("call_timeout"), &callTimeoutValue)...)
+	if _, err := callTimeout(callTimeoutValue.ValueString()); err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("call_timeout"), "Invalid duration", err.Error())
+	}
+
+	var waitForReady WaitForReadyModel
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("wait_for_ready").AtName("timeout"), &waitForReady.Timeout)...)
+	if _, err := waitForReady.timeout(); err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("wait_for_ready").AtName("timeout"), "Invalid duration", err.Error())
+	}
+}
+
 func (p *permifyProvider) Configure(ctx context.Context, req provider.ConfigureRequest, resp *provider.ConfigureResponse) {
+	if !req.Config.Raw.IsFullyKnown() {
+		if req.ClientCapabilities.DeferralAllowed {
+			tflog.Info(ctx, "Deferring Permify resources until the provider configuration is known")
+			resp.Deferred = &provider.Deferred{Reason: provider.DeferredReasonProviderConfigUnknown}
+			return
+		}
+
+		tflog.Warn(ctx, "Permify provider configuration is not known yet, the client will be created once it is")
+		// The lint rules run offline, so they still apply at plan time when
+		// they are known.
+		var schemaLint SchemaLintModel
+		resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("schema_lint").AtName("rules"), &schemaLint.Rules)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		pending := &providerData{configUnknown: true, schemaLint: schemaLint.rules()}
+		resp.DataSourceData = pending
+		resp.ResourceData = pending
+		return
+	}
+
 	var data PermifyProviderModel
 
 	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
@@ -60,27 +175,93 @@ func (p *permifyProvider) Configure(ctx context.Context, req provider.ConfigureR
 		return
 	}
 
-	if data.Endpoint.IsNull() || data.Endpoint.ValueString() == "" {
-		data.Endpoint = types.StringValue("localhost:3478")
+	if err := resolveProviderConfig(ctx, &data); err != nil {
+		resp.Diagnostics.AddError("Invalid Permify provider configuration", err.Error())
+		return
+	}
+
+	timeout, err := callTimeout(data.CallTimeout.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError("Invalid Permify provider configuration", err.Error())
+		return
+	}
+
+	options, err := getOptions(data)
+	if err != nil {
+		resp.Diagnostics.AddError("Invalid Permify TLS configuration", err.Error())
+		return
+	}
+	options = append(options, grpc.WithUserAgent(userAgent(p.version, req.TerraformVersion)))
+
+	retry, err := data.Retry.policy()
+	if err != nil {
+		resp.Diagnostics.AddError("Invalid Permify retry configuration", err.Error())
 		return
 	}
 
+	if data.WaitForReady != nil {
+		readyTimeout, err := data.WaitForReady.timeout()
+		if err != nil {
+			resp.Diagnostics.AddError("Invalid Permify provider configuration", err.Error())
+			return
+		}
+		check := readinessCheck{
+			endpoint:    data.Endpoint.ValueString(),
+			options:     options,
+			timeout:     readyTimeout,
+			interval:    defaultReadyInterval,
+			callTimeout: timeout,
+		}
+		if err := check.wait(ctx); err != nil {
+			resp.Diagnostics.AddError(
+				"Permify is not ready",
+				fmt.Sprintf("Permify at %s did not become ready within %s.\n\nLast error: %s", check.endpoint, readyTimeout, err),
+			)
+			return
+		}
+	}
+
+	options = append(options, grpc.WithChainUnaryInterceptor(retry.unaryInterceptor, callTimeoutInterceptor(timeout)))
+
 	client, err := permify_grpc.NewClient(
 		permify_grpc.Config{
 			Endpoint: data.Endpoint.ValueString(),
 		},
-		getOptions(data)...,
+		options...,
 	)
 	if err != nil {
 		resp.Diagnostics.AddError("Failed to initialize Permify client", err.Error())
+		return
 	}
-	resp.DataSourceData = client
-	resp.ResourceData = client
+	configured := &providerData{client: client, schemaLint: data.SchemaLint.rules()}
+	resp.DataSourceData = configured
+	resp.ResourceData = configured
+}
+
+// configUnknownDiagnostic is reported by data sources that are read while the
+// provider configuration is unknown and Terraform cannot defer them.
+func configUnknownDiagnostic() diag.Diagnostic {
+	return diag.NewErrorDiagnostic(
+		"Permify provider configuration not known",
+		"The Permify provider configuration depends on values that are only known after apply, so this data source cannot be read yet. "+
+			"Apply the resources the provider configuration depends on first, for example with -target.",
+	)
+}
+
+// clientNotConfiguredDiagnostic is reported by resources and data sources that
+// are used without a Permify client, instead of dereferencing a nil client.
+func clientNotConfiguredDiagnostic() diag.Diagnostic {
+	return diag.NewErrorDiagnostic(
+		"Permify client not configured",
+		"The Permify provider did not create a client, so this operation cannot reach Permify. "+
+			"Check the provider configuration and any errors reported while configuring it.",
+	)
 }
 
 func (p *permifyProvider) Resources(ctx context.Context) []func() resource.Resource {
 	return []func() resource.Resource{
 		NewSchemaResource,
+		NewSchemaPartialResource,
 		NewTenantResource,
 		NewBundlesResource,
 	}
@@ -89,6 +270,8 @@ func (p *permifyProvider) Resources(ctx context.Context) []func() resource.Resou
 func (p *permifyProvider) DataSources(ctx context.Context) []func() datasource.DataSource {
 	return []func() datasource.DataSource{
 		NewTenantDataSource,
+		NewSchemaDataSource,
+		NewSchemaVersionsDataSource,
 	}
 }
 
@@ -104,19 +287,93 @@ func New(version string) func() provider.Provider {
 	}
 }
 
-func getOptions(data PermifyProviderModel) []grpc.DialOption {
+func getOptions(data PermifyProviderModel) ([]grpc.DialOption, error) {
+	transport := insecure.NewCredentials()
+	if data.TLS.enabled() {
+		var err error
+		transport, err = data.TLS.transportCredentials()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	timeout, err := callTimeout(data.CallTimeout.ValueString())
+	if err != nil {
+		return nil, err
+	}
+
 	options := []grpc.DialOption{
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithTransportCredentials(transport),
+	}
+	if tokens := data.tokenSource(timeout); tokens != nil {
+		options = append(options, grpc.WithPerRPCCredentials(tokenCredentials{tokens: tokens}))
+	}
+	if len(data.Headers.Elements()) > 0 {
+		headers := make(headerCredentials, len(data.Headers.Elements()))
+		for name, value := range data.Headers.Elements() {
+			headers[strings.ToLower(name)] = value.(types.String).ValueString()
+		}
+		options = append(options, grpc.WithPerRPCCredentials(headers))
 	}
-	if !data.Token.IsNull() && data.Token.ValueString() != "" {
-		options = append(options, grpc.WithUnaryInterceptor(authInterceptor(data.Token.ValueString())))
+
+	return options, nil
+}
+
+// tokenSource returns the source of the authorization metadata, or nil when no
+// credentials are configured.  Token requests give up after timeout.
+func (m PermifyProviderModel) tokenSource(timeout time.Duration) oauth2.TokenSource {
+	if m.OAuth2 != nil {
+		return m.OAuth2.tokenSource(timeout)
+	}
+	if !m.Token.IsNull() && m.Token.ValueString() != "" {
+		return oauth2.StaticTokenSource(&oauth2.Token{AccessToken: m.Token.ValueString()})
+	}
+	return nil
+}
+
+// tokenCredentials attaches the authorization metadata to every RPC, unary and
+// streaming alike.
+type tokenCredentials struct {
+	tokens oauth2.TokenSource
+}
+
+func (c tokenCredentials) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	token, err := c.tokens.Token()
+	if err != nil {
+		return nil, status.Errorf(codes.Unauthenticated, "failed to obtain access token: %v", err)
 	}
+	return map[string]string{"authorization": authorization(token)}, nil
+}
+
+// RequireTransportSecurity allows tokens to be sent to Permify instances that
+// are dialed without TLS.
+func (c tokenCredentials) RequireTransportSecurity() bool {
+	return false
+}
+
+// headerNamePattern matches the header names that are accepted in `headers`.
+var headerNamePattern = regexp.MustCompile(`^[0-9A-Za-z_.-]+$`)
+
+// reservedHeaders are set by the provider or by gRPC itself.
+var reservedHeaders = []string{"authorization", "user-agent", "content-type", "te"}
+
+// headerCredentials attaches the configured headers to every RPC, unary and
+// streaming alike.
+type headerCredentials map[string]string
+
+func (c headerCredentials) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	return c, nil
+}
 
-	return options
+func (c headerCredentials) RequireTransportSecurity() bool {
+	return false
 }
 
-func authInterceptor(token string) grpc.UnaryClientInterceptor {
-	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
-		return invoker(metadata.AppendToOutgoingContext(ctx, "authorization", token), method, req, reply, cc, opts...)
+// userAgent identifies the provider and the Terraform version driving it, in
+// the format Terraform providers conventionally use.
+func userAgent(providerVersion string, terraformVersion string) string {
+	if terraformVersion == "" {
+		terraformVersion = "unknown"
 	}
+	return fmt.Sprintf("Terraform/%s (+https://www.terraform.io) terraform-provider-permify/%s", terraformVersion, providerVersion)
 }
@@ -0,0 +1,210 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"gopkg.in/yaml.v3"
+)
+
+// Environment variables consulted for settings that are not set in the
+// provider block.
+const (
+	envEndpoint                 = "PERMIFY_ENDPOINT"
+	envToken                    = "PERMIFY_TOKEN"
+	envProfile                  = "PERMIFY_PROFILE"
+	envConfigFile               = "PERMIFY_CONFIG_FILE"
+	envTLS                      = "PERMIFY_TLS"
+	envTLSCACertificateFile     = "PERMIFY_TLS_CA_CERTIFICATE_FILE"
+	envTLSClientCertificateFile = "PERMIFY_TLS_CLIENT_CERTIFICATE_FILE"
+	envTLSClientKeyFile         = "PERMIFY_TLS_CLIENT_KEY_FILE"
+	envTLSServerName            = "PERMIFY_TLS_SERVER_NAME"
+	envTLSInsecureSkipVerify    = "PERMIFY_TLS_INSECURE_SKIP_VERIFY"
+)
+
+// defaultEndpoint is used when no endpoint is configured anywhere, and matches
+// the gRPC port of a Permify server running locally with default settings.
+const defaultEndpoint = "localhost:3478"
+
+// defaultConfigFile is the profile file used when neither `config_file` nor
+// PERMIFY_CONFIG_FILE is set, relative to the user's home directory.
+var defaultConfigFile = filepath.Join(".permify", "config.yaml")
+
+type configFile struct {
+	Profiles map[string]profileConfig `yaml:"profiles"`
+}
+
+type profileConfig struct {
+	Endpoint string            `yaml:"endpoint"`
+	Token    string            `yaml:"token"`
+	TLS      *profileTLSConfig `yaml:"tls"`
+}
+
+type profileTLSConfig struct {
+	Enabled               *bool  `yaml:"enabled"`
+	CACertificate         string `yaml:"ca_certificate"`
+	CACertificateFile     string `yaml:"ca_certificate_file"`
+	ClientCertificate     string `yaml:"client_certificate"`
+	ClientCertificateFile string `yaml:"client_certificate_file"`
+	ClientKey             string `yaml:"client_key"`
+	ClientKeyFile         string `yaml:"client_key_file"`
+	ServerName            string `yaml:"server_name"`
+	InsecureSkipVerify    *bool  `yaml:"insecure_skip_verify"`
+}
+
+// resolveProviderConfig fills the settings that are not set in the provider
+// block, first from the environment and then from the selected profile.  The
+// source of every setting is logged; values are not, as some are secrets.
+func resolveProviderConfig(ctx context.Context, data *PermifyProviderModel) error {
+	r := settingResolver{ctx: ctx}
+
+	r.string("config_file", &data.ConfigFile, envConfigFile, "")
+	r.string("profile", &data.Profile, envProfile, "")
+	if data.Profile.ValueString() != "" {
+		profile, err := loadProfile(data.ConfigFile.ValueString(), data.Profile.ValueString())
+		if err != nil {
+			return err
+		}
+		r.profileName = data.Profile.ValueString()
+		r.profile = profile
+	}
+
+	r.string("endpoint", &data.Endpoint, envEndpoint, r.profile.Endpoint)
+	if !data.Endpoint.IsUnknown() && data.Endpoint.ValueString() == "" {
+		data.Endpoint = types.StringValue(defaultEndpoint)
+		r.resolved("endpoint", "default")
+	}
+	r.string("token", &data.Token, envToken, r.profile.Token)
+
+	profileTLS := r.profile.TLS
+	if profileTLS == nil {
+		profileTLS = &profileTLSConfig{}
+	}
+	tls := data.TLS
+	if tls == nil {
+		tls = &TLSModel{}
+	}
+	if err := r.bool("tls.enabled", &tls.Enabled, envTLS, profileTLS.Enabled); err != nil {
+		return err
+	}
+	r.pem("tls.ca_certificate", &tls.CACertificate, &tls.CACertificateFile, envTLSCACertificateFile, profileTLS.CACertificate, profileTLS.CACertificateFile)
+	r.pem("tls.client_certificate", &tls.ClientCertificate, &tls.ClientCertificateFile, envTLSClientCertificateFile, profileTLS.ClientCertificate, profileTLS.ClientCertificateFile)
+	r.pem("tls.client_key", &tls.ClientKey, &tls.ClientKeyFile, envTLSClientKeyFile, profileTLS.ClientKey, profileTLS.ClientKeyFile)
+	r.string("tls.server_name", &tls.ServerName, envTLSServerName, profileTLS.ServerName)
+	if err := r.bool("tls.insecure_skip_verify", &tls.InsecureSkipVerify, envTLSInsecureSkipVerify, profileTLS.InsecureSkipVerify); err != nil {
+		return err
+	}
+	if data.TLS == nil && r.resolvedTLS {
+		data.TLS = tls
+	}
+
+	return nil
+}
+
+func loadProfile(path string, name string) (profileConfig, error) {
+	if path == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return profileConfig{}, fmt.Errorf("locating the Permify config file: %w", err)
+		}
+		path = filepath.Join(home, defaultConfigFile)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return profileConfig{}, fmt.Errorf("reading the Permify config file: %w", err)
+	}
+	var config configFile
+	if err := yaml.Unmarshal(content, &config); err != nil {
+		return profileConfig{}, fmt.Errorf("parsing the Permify config file %s: %w", path, err)
+	}
+	profile, ok := config.Profiles[name]
+	if !ok {
+		return profileConfig{}, fmt.Errorf("profile %q not found in %s", name, path)
+	}
+	return profile, nil
+}
+
+type settingResolver struct {
+	ctx         context.Context
+	profileName string
+	profile     profileConfig
+	resolvedTLS bool
+}
+
+func (r *settingResolver) string(name string, value *types.String, env string, fromProfile string) {
+	var source string
+	switch {
+	case !value.IsNull():
+		source = "provider configuration"
+	case os.Getenv(env) != "":
+		*value = types.StringValue(os.Getenv(env))
+		source = "environment variable " + env
+	case fromProfile != "":
+		*value = types.StringValue(fromProfile)
+		source = fmt.Sprintf("profile %q", r.profileName)
+	default:
+		return
+	}
+	r.resolved(name, source)
+}
+
+// pem resolves a PEM setting that can be given inline or as a file as one
+// setting, so that a file from one source never hides inline PEM from another.
+func (r *settingResolver) pem(name string, inline *types.String, file *types.String, env string, fromProfile string, fileFromProfile string) {
+	var source string
+	switch {
+	case !inline.IsNull() || !file.IsNull():
+		source = "provider configuration"
+	case os.Getenv(env) != "":
+		*file = types.StringValue(os.Getenv(env))
+		source = "environment variable " + env
+	case fromProfile != "":
+		*inline = types.StringValue(fromProfile)
+		source = fmt.Sprintf("profile %q", r.profileName)
+	case fileFromProfile != "":
+		*file = types.StringValue(fileFromProfile)
+		source = fmt.Sprintf("profile %q", r.profileName)
+	default:
+		return
+	}
+	r.resolved(name, source)
+}
+
+func (r *settingResolver) bool(name string, value *types.Bool, env string, fromProfile *bool) error {
+	var source string
+	switch {
+	case !value.IsNull():
+		source = "provider configuration"
+	case os.Getenv(env) != "":
+		parsed, err := strconv.ParseBool(os.Getenv(env))
+		if err != nil {
+			return fmt.Errorf("invalid value for %s: %w", env, err)
+		}
+		*value = types.BoolValue(parsed)
+		source = "environment variable " + env
+	case fromProfile != nil:
+		*value = types.BoolValue(*fromProfile)
+		source = fmt.Sprintf("profile %q", r.profileName)
+	default:
+		return nil
+	}
+	r.resolved(name, source)
+	return nil
+}
+
+func (r *settingResolver) resolved(name string, source string) {
+	if strings.HasPrefix(name, "tls.") {
+		r.resolvedTLS = true
+	}
+	tflog.Debug(r.ctx, "Resolved Permify provider setting", map[string]any{
+		"setting": name,
+		"source":  source,
+	})
+}
@@ -0,0 +1,194 @@
+package provider
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/stretchr/testify/require"
+)
+
+const testConfigFile = `
+profiles:
+  local:
+    endpoint: localhost:3478
+  staging:
+    endpoint: permify.staging:3478
+    token: staging-key
+    tls:
+      ca_certificate_file: /etc/ssl/staging-ca.pem
+      server_name: permify.staging
+`
+
+func writeTestConfigFile(t *testing.T) string {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(testConfigFile), 0o600))
+	return path
+}
+
+func TestResolveProviderConfig(t *testing.T) {
+	configFile := writeTestConfigFile(t)
+
+	tests := []struct {
+		name    string
+		env     map[string]string
+		data    PermifyProviderModel
+		want    PermifyProviderModel
+		wantErr bool
+	}{
+		{
+			name: "nothing set",
+			want: PermifyProviderModel{
+				Endpoint: types.StringValue(defaultEndpoint),
+			},
+		},
+		{
+			name: "environment",
+			env: map[string]string{
+				envEndpoint:              "permify.env:3478",
+				envToken:                 "env-key",
+				envTLSServerName:         "permify.env",
+				envTLSInsecureSkipVerify: "true",
+			},
+			want: PermifyProviderModel{
+				Endpoint: types.StringValue("permify.env:3478"),
+				Token:    types.StringValue("env-key"),
+				TLS: &TLSModel{
+					ServerName:         types.StringValue("permify.env"),
+					InsecureSkipVerify: types.BoolValue(true),
+				},
+			},
+		},
+		{
+			name: "configuration takes precedence over the environment",
+			env: map[string]string{
+				envEndpoint: "permify.env:3478",
+				envToken:    "env-key",
+			},
+			data: PermifyProviderModel{
+				Endpoint: types.StringValue("permify.hcl:3478"),
+			},
+			want: PermifyProviderModel{
+				Endpoint: types.StringValue("permify.hcl:3478"),
+				Token:    types.StringValue("env-key"),
+			},
+		},
+		{
+			name: "profile",
+			data: PermifyProviderModel{
+				Profile:    types.StringValue("staging"),
+				ConfigFile: types.StringValue(configFile),
+			},
+			want: PermifyProviderModel{
+				Endpoint:   types.StringValue("permify.staging:3478"),
+				Token:      types.StringValue("staging-key"),
+				Profile:    types.StringValue("staging"),
+				ConfigFile: types.StringValue(configFile),
+				TLS: &TLSModel{
+					CACertificateFile: types.StringValue("/etc/ssl/staging-ca.pem"),
+					ServerName:        types.StringValue("permify.staging"),
+				},
+			},
+		},
+		{
+			name: "profile selected by the environment",
+			env: map[string]string{
+				envProfile:    "local",
+				envConfigFile: configFile,
+			},
+			want: PermifyProviderModel{
+				Endpoint:   types.StringValue("localhost:3478"),
+				Profile:    types.StringValue("local"),
+				ConfigFile: types.StringValue(configFile),
+			},
+		},
+		{
+			name: "environment takes precedence over the profile",
+			env: map[string]string{
+				envToken:                "env-key",
+				envTLSCACertificateFile: "/etc/ssl/env-ca.pem",
+			},
+			data: PermifyProviderModel{
+				Profile:    types.StringValue("staging"),
+				ConfigFile: types.StringValue(configFile),
+				TLS: &TLSModel{
+					Enabled: types.BoolValue(true),
+				},
+			},
+			want: PermifyProviderModel{
+				Endpoint:   types.StringValue("permify.staging:3478"),
+				Token:      types.StringValue("env-key"),
+				Profile:    types.StringValue("staging"),
+				ConfigFile: types.StringValue(configFile),
+				TLS: &TLSModel{
+					Enabled:           types.BoolValue(true),
+					CACertificateFile: types.StringValue("/etc/ssl/env-ca.pem"),
+					ServerName:        types.StringValue("permify.staging"),
+				},
+			},
+		},
+		{
+			name: "inline PEM in the configuration hides the profile's file",
+			data: PermifyProviderModel{
+				Profile:    types.StringValue("staging"),
+				ConfigFile: types.StringValue(configFile),
+				TLS: &TLSModel{
+					CACertificate: types.StringValue("inline"),
+				},
+			},
+			want: PermifyProviderModel{
+				Endpoint:   types.StringValue("permify.staging:3478"),
+				Token:      types.StringValue("staging-key"),
+				Profile:    types.StringValue("staging"),
+				ConfigFile: types.StringValue(configFile),
+				TLS: &TLSModel{
+					CACertificate: types.StringValue("inline"),
+					ServerName:    types.StringValue("permify.staging"),
+				},
+			},
+		},
+		{
+			name: "unknown profile",
+			data: PermifyProviderModel{
+				Profile:    types.StringValue("prod"),
+				ConfigFile: types.StringValue(configFile),
+			},
+			wantErr: true,
+		},
+		{
+			name: "missing config file",
+			data: PermifyProviderModel{
+				Profile:    types.StringValue("local"),
+				ConfigFile: types.StringValue(filepath.Join(t.TempDir(), "missing.yaml")),
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid boolean in the environment",
+			env: map[string]string{
+				envTLS: "sometimes",
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, env := range []string{envEndpoint, envToken, envProfile, envConfigFile, envTLS, envTLSCACertificateFile,
+				envTLSClientCertificateFile, envTLSClientKeyFile, envTLSServerName, envTLSInsecureSkipVerify} {
+				t.Setenv(env, tt.env[env])
+			}
+
+			data := tt.data
+			err := resolveProviderConfig(context.Background(), &data)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, data)
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/objectvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+)
+
+type OAuth2Model struct {
+	TokenURL     types.String   `tfsdk:"token_url"`
+	ClientID     types.String   `tfsdk:"client_id"`
+	ClientSecret types.String   `tfsdk:"client_secret"`
+	Scopes       []types.String `tfsdk:"scopes"`
+	Audience     types.String   `tfsdk:"audience"`
+}
+
+func oauth2Block() schema.Block {
+	return schema.SingleNestedBlock{
+		MarkdownDescription: "Obtain access tokens with the OAuth2 client credentials grant instead of a static `token`.  Tokens are cached and refreshed shortly before they expire.",
+		Validators: []validator.Object{
+			objectvalidator.ConflictsWith(path.MatchRoot("token")),
+		},
+		Attributes: map[string]schema.Attribute{
+			"token_url": schema.StringAttribute{
+				MarkdownDescription: "Token endpoint of the authorization server.",
+				Required:            true,
+			},
+			"client_id": schema.StringAttribute{
+				MarkdownDescription: "OAuth2 client ID.",
+				Required:            true,
+			},
+			"client_secret": schema.StringAttribute{
+				MarkdownDescription: "OAuth2 client secret.",
+				Required:            true,
+				Sensitive:           true,
+			},
+			"scopes": schema.ListAttribute{
+				MarkdownDescription: "Scopes to request.",
+				Optional:            true,
+				ElementType:         types.StringType,
+			},
+			"audience": schema.StringAttribute{
+				MarkdownDescription: "Audience to request, for authorization servers that require one.",
+				Optional:            true,
+			},
+		},
+	}
+}
+
+// tokenSource fetches tokens with an HTTP client that gives up after timeout,
+// as oauth2 does not pass the RPC's context on to the token endpoint.
+func (m *OAuth2Model) tokenSource(timeout time.Duration) oauth2.TokenSource {
+	scopes := make([]string, len(m.Scopes))
+	for i, scope := range m.Scopes {
+		scopes[i] = scope.ValueString()
+	}
+
+	config := clientcredentials.Config{
+		ClientID:     m.ClientID.ValueString(),
+		ClientSecret: m.ClientSecret.ValueString(),
+		TokenURL:     m.TokenURL.ValueString(),
+		Scopes:       scopes,
+	}
+	if m.Audience.ValueString() != "" {
+		config.EndpointParams = url.Values{"audience": []string{m.Audience.ValueString()}}
+	}
+
+	// The token source outlives the Configure request, so it must not be bound
+	// to the request context.
+	ctx := context.WithValue(context.Background(), oauth2.HTTPClient, &http.Client{Timeout: timeout})
+	return config.TokenSource(ctx)
+}
+
+// authorization formats a token for the authorization metadata.  Static
+// tokens carry no type and are sent verbatim.
+func authorization(token *oauth2.Token) string {
+	if token.TokenType == "" {
+		return token.AccessToken
+	}
+	return token.Type() + " " + token.AccessToken
+}
@@ -0,0 +1,204 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	permify_payload "buf.build/gen/go/permifyco/permify/protocolbuffers/go/base/v1"
+	permify_grpc "github.com/Permify/permify-go/grpc"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fakeTokenEndpoint issues numbered access tokens with the client credentials
+// grant and records the form values of every token request.
+type fakeTokenEndpoint struct {
+	*httptest.Server
+
+	expiresIn int
+	fail      bool
+
+	mu       sync.Mutex
+	requests []map[string]string
+}
+
+func startFakeTokenEndpoint(t *testing.T, expiresIn int) *fakeTokenEndpoint {
+	endpoint := &fakeTokenEndpoint{expiresIn: expiresIn}
+	endpoint.Server = httptest.NewServer(http.HandlerFunc(endpoint.serveHTTP))
+	t.Cleanup(endpoint.Close)
+	return endpoint
+}
+
+func (e *fakeTokenEndpoint) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	clientID, clientSecret, ok := r.BasicAuth()
+	if !ok {
+		clientID, clientSecret = r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+	}
+
+	e.mu.Lock()
+	e.requests = append(e.requests, map[string]string{
+		"grant_type":    r.PostForm.Get("grant_type"),
+		"client_id":     clientID,
+		"client_secret": clientSecret,
+		"scope":         r.PostForm.Get("scope"),
+		"audience":      r.PostForm.Get("audience"),
+	})
+	count := len(e.requests)
+	e.mu.Unlock()
+
+	if e.fail {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"error":"invalid_client"}`))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"access_token": fmt.Sprintf("token-%d", count),
+		"token_type":   "bearer",
+		"expires_in":   e.expiresIn,
+	})
+}
+
+func (e *fakeTokenEndpoint) tokenRequests() []map[string]string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]map[string]string(nil), e.requests...)
+}
+
+func newOAuth2TestClient(t *testing.T, fake *fakePermify, tokenURL string) *permify_grpc.Client {
+	options, err := getOptions(PermifyProviderModel{
+		OAuth2: &OAuth2Model{
+			TokenURL:     types.StringValue(tokenURL),
+			ClientID:     types.StringValue("terraform"),
+			ClientSecret: types.StringValue("s3cret"),
+			Scopes:       []types.String{types.StringValue("tenants"), types.StringValue("schemas")},
+			Audience:     types.StringValue("permify"),
+		},
+	})
+	require.NoError(t, err)
+	client, err := permify_grpc.NewClient(permify_grpc.Config{Endpoint: fake.endpoint}, options...)
+	require.NoError(t, err)
+	return client
+}
+
+func listTenants(t *testing.T, client *permify_grpc.Client) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err := client.Tenancy.List(ctx, &permify_payload.TenantListRequest{PageSize: 1})
+	return err
+}
+
+func TestOAuth2TokenIsCached(t *testing.T) {
+	fake := startFakePermify(t)
+	tokens := startFakeTokenEndpoint(t, 3600)
+	client := newOAuth2TestClient(t, fake, tokens.URL)
+
+	require.NoError(t, listTenants(t, client))
+	require.NoError(t, listTenants(t, client))
+
+	requests := tokens.tokenRequests()
+	require.Len(t, requests, 1)
+	require.Equal(t, map[string]string{
+		"grant_type":    "client_credentials",
+		"client_id":     "terraform",
+		"client_secret": "s3cret",
+		"scope":         "tenants schemas",
+		"audience":      "permify",
+	}, requests[0])
+
+	for _, md := range fake.received() {
+		require.Equal(t, []string{"Bearer token-1"}, md.Get("authorization"))
+	}
+}
+
+func TestOAuth2TokenIsRefreshedBeforeExpiry(t *testing.T) {
+	fake := startFakePermify(t)
+	// Tokens that expire within the refresh window are replaced on every call.
+	tokens := startFakeTokenEndpoint(t, 5)
+	client := newOAuth2TestClient(t, fake, tokens.URL)
+
+	require.NoError(t, listTenants(t, client))
+	require.NoError(t, listTenants(t, client))
+
+	require.Len(t, tokens.tokenRequests(), 2)
+	received := fake.received()
+	require.Len(t, received, 2)
+	require.Equal(t, []string{"Bearer token-1"}, received[0].Get("authorization"))
+	require.Equal(t, []string{"Bearer token-2"}, received[1].Get("authorization"))
+}
+
+func TestOAuth2TokenEndpointFailure(t *testing.T) {
+	fake := startFakePermify(t)
+	tokens := startFakeTokenEndpoint(t, 3600)
+	tokens.fail = true
+	client := newOAuth2TestClient(t, fake, tokens.URL)
+
+	err := listTenants(t, client)
+	require.Error(t, err)
+	require.Equal(t, codes.Unauthenticated, status.Code(err))
+	require.Empty(t, fake.received())
+}
+
+func TestOAuth2TokenEndpointHangs(t *testing.T) {
+	fake := startFakePermify(t)
+	release := make(chan struct{})
+	hung := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	t.Cleanup(hung.Close)
+	t.Cleanup(func() { close(release) })
+
+	options, err := getOptions(PermifyProviderModel{
+		CallTimeout: types.StringValue("200ms"),
+		OAuth2: &OAuth2Model{
+			TokenURL:     types.StringValue(hung.URL),
+			ClientID:     types.StringValue("terraform"),
+			ClientSecret: types.StringValue("s3cret"),
+		},
+	})
+	require.NoError(t, err)
+	client, err := permify_grpc.NewClient(permify_grpc.Config{Endpoint: fake.endpoint}, options...)
+	require.NoError(t, err)
+
+	done := make(chan error, 1)
+	go func() { done <- listTenants(t, client) }()
+	select {
+	case err := <-done:
+		require.Equal(t, codes.Unauthenticated, status.Code(err))
+		require.ErrorContains(t, err, "Client.Timeout exceeded")
+	case <-time.After(3 * time.Second):
+		t.Fatal("the token request was not given up after the call timeout")
+	}
+	require.Empty(t, fake.received())
+}
+
+func TestStaticTokenIsSentVerbatim(t *testing.T) {
+	fake := startFakePermify(t)
+	options, err := getOptions(PermifyProviderModel{Token: types.StringValue("pre-shared-key")})
+	require.NoError(t, err)
+	client, err := permify_grpc.NewClient(permify_grpc.Config{Endpoint: fake.endpoint}, options...)
+	require.NoError(t, err)
+
+	require.NoError(t, listTenants(t, client))
+
+	received := fake.received()
+	require.Len(t, received, 1)
+	require.Equal(t, []string{"pre-shared-key"}, received[0].Get("authorization"))
+}
@@ -0,0 +1,135 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"buf.build/gen/go/permifyco/permify/grpc/go/base/v1/basev1grpc"
+	permify_payload "buf.build/gen/go/permifyco/permify/protocolbuffers/go/base/v1"
+	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	defaultReadyTimeout  = 2 * time.Minute
+	defaultReadyInterval = time.Second
+)
+
+type WaitForReadyModel struct {
+	Timeout types.String `tfsdk:"timeout"`
+}
+
+func waitForReadyBlock() schema.Block {
+	return schema.SingleNestedBlock{
+		MarkdownDescription: "Wait for Permify to accept calls before using it, for when it is started in the same run.  The provider " +
+			"polls the standard gRPC health service, or lists tenants when the server does not offer it.",
+		Attributes: map[string]schema.Attribute{
+			"timeout": schema.StringAttribute{
+				MarkdownDescription: "How long to wait, as a positive Go duration such as `5m`.  Defaults to `2m`.",
+				Optional:            true,
+			},
+		},
+	}
+}
+
+func (m *WaitForReadyModel) timeout() (time.Duration, error) {
+	if m.Timeout.IsNull() || m.Timeout.ValueString() == "" {
+		return defaultReadyTimeout, nil
+	}
+	timeout, err := time.ParseDuration(m.Timeout.ValueString())
+	if err != nil {
+		return 0, fmt.Errorf("invalid wait_for_ready timeout: %w", err)
+	}
+	if timeout <= 0 {
+		return 0, fmt.Errorf("wait_for_ready timeout must be positive, got %s", timeout)
+	}
+	return timeout, nil
+}
+
+// readinessCheck polls a Permify endpoint until it answers.  It dials its own
+// connection so that probes are not subject to the client's retry policy.
+type readinessCheck struct {
+	endpoint    string
+	options     []grpc.DialOption
+	timeout     time.Duration
+	interval    time.Duration
+	callTimeout time.Duration
+}
+
+// wait returns nil once the endpoint is ready, or the last error seen when it
+// is not ready within the timeout.
+func (c readinessCheck) wait(ctx context.Context) error {
+	conn, err := grpc.NewClient(c.endpoint, c.options...)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	deadline, _ := ctx.Deadline()
+
+	probe := readinessProbe{
+		health:    healthpb.NewHealthClient(conn),
+		tenancy:   basev1grpc.NewTenancyClient(conn),
+		useHealth: true,
+	}
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+	var lastErr error
+	for {
+		err := probe.check(ctx, c.callTimeout)
+		if err == nil {
+			tflog.Debug(ctx, "Permify is ready", map[string]any{"endpoint": c.endpoint})
+			return nil
+		}
+		// A probe cut short by the end of the wait says less than the one
+		// before it.
+		if lastErr != nil && !time.Now().Before(deadline) {
+			return lastErr
+		}
+		lastErr = err
+		tflog.Debug(ctx, "Waiting for Permify to become ready", map[string]any{"endpoint": c.endpoint, "error": err.Error()})
+
+		select {
+		case <-ctx.Done():
+			return lastErr
+		case <-ticker.C:
+		}
+	}
+}
+
+type readinessProbe struct {
+	health    healthpb.HealthClient
+	tenancy   basev1grpc.TenancyClient
+	useHealth bool
+}
+
+func (p *readinessProbe) check(ctx context.Context, callTimeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, callTimeout)
+	defer cancel()
+
+	if p.useHealth {
+		result, err := p.health.Check(ctx, &healthpb.HealthCheckRequest{})
+		switch {
+		case status.Code(err) == codes.Unimplemented:
+			tflog.Info(ctx, "Permify does not offer the gRPC health service, listing tenants instead")
+			p.useHealth = false
+		case err != nil:
+			return err
+		case result.Status != healthpb.HealthCheckResponse_SERVING:
+			return fmt.Errorf("health service reports %s", result.Status)
+		default:
+			return nil
+		}
+	}
+
+	_, err := p.tenancy.List(ctx, &permify_payload.TenantListRequest{PageSize: 1})
+	return err
+}
@@ -0,0 +1,140 @@
+package provider
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/provider"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// unusedEndpoint returns an address nothing listens on.
+func unusedEndpoint(t *testing.T) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	endpoint := listener.Addr().String()
+	require.NoError(t, listener.Close())
+	return endpoint
+}
+
+func testReadinessCheck(endpoint string, timeout time.Duration) readinessCheck {
+	return readinessCheck{
+		endpoint:    endpoint,
+		options:     []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())},
+		timeout:     timeout,
+		interval:    10 * time.Millisecond,
+		callTimeout: time.Second,
+	}
+}
+
+func TestReadinessCheck(t *testing.T) {
+	t.Run("serving", func(t *testing.T) {
+		fake := startFakePermify(t)
+		require.NoError(t, testReadinessCheck(fake.endpoint, time.Second).wait(context.Background()))
+	})
+
+	t.Run("becomes serving", func(t *testing.T) {
+		fake := startFakePermify(t)
+		fake.health.SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+		time.AfterFunc(50*time.Millisecond, func() {
+			fake.health.SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
+		})
+		require.NoError(t, testReadinessCheck(fake.endpoint, 5*time.Second).wait(context.Background()))
+	})
+
+	t.Run("never serving", func(t *testing.T) {
+		fake := startFakePermify(t)
+		fake.health.SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+		err := testReadinessCheck(fake.endpoint, 100*time.Millisecond).wait(context.Background())
+		require.ErrorContains(t, err, "health service reports NOT_SERVING")
+	})
+
+	t.Run("falls back to listing tenants", func(t *testing.T) {
+		noHealth := &flakyMethod{method: healthpb.Health_Check_FullMethodName, failures: 1000, code: codes.Unimplemented}
+		fake := startFakePermify(t, noHealth.serverOption())
+		require.NoError(t, testReadinessCheck(fake.endpoint, time.Second).wait(context.Background()))
+		require.Equal(t, 1, noHealth.callCount())
+		require.Len(t, fake.received(), 1)
+	})
+
+	t.Run("nothing listening", func(t *testing.T) {
+		err := testReadinessCheck(unusedEndpoint(t), 100*time.Millisecond).wait(context.Background())
+		require.Error(t, err)
+	})
+}
+
+func TestConfigureWaitsForReady(t *testing.T) {
+	t.Setenv(envProfile, "")
+	endpoint := unusedEndpoint(t)
+
+	var resp provider.ConfigureResponse
+	New("test")().Configure(context.Background(), provider.ConfigureRequest{
+		Config: testProviderConfig(t, map[string]tftypes.Value{
+			"endpoint": tftypes.NewValue(tftypes.String, endpoint),
+			"wait_for_ready": tftypes.NewValue(tftypes.Object{AttributeTypes: map[string]tftypes.Type{"timeout": tftypes.String}}, map[string]tftypes.Value{
+				"timeout": tftypes.NewValue(tftypes.String, "200ms"),
+			}),
+		}),
+	}, &resp)
+
+	require.Len(t, resp.Diagnostics, 1)
+	require.Equal(t, "Permify is not ready", resp.Diagnostics[0].Summary())
+	require.Contains(t, resp.Diagnostics[0].Detail(), "Permify at "+endpoint+" did not become ready within 200ms")
+	require.Contains(t, resp.Diagnostics[0].Detail(), "Last error: ")
+	require.Nil(t, resp.ResourceData)
+}
+
+func TestValidateConfigTimeouts(t *testing.T) {
+	waitForReady := func(timeout tftypes.Value) map[string]tftypes.Value {
+		return map[string]tftypes.Value{
+			"wait_for_ready": tftypes.NewValue(tftypes.Object{AttributeTypes: map[string]tftypes.Type{"timeout": tftypes.String}}, map[string]tftypes.Value{
+				"timeout": timeout,
+			}),
+		}
+	}
+
+	tests := []struct {
+		name     string
+		values   map[string]tftypes.Value
+		wantErr  string
+		wantPath path.Path
+	}{
+		{name: "no block", values: map[string]tftypes.Value{}},
+		{name: "default", values: waitForReady(tftypes.NewValue(tftypes.String, nil))},
+		{name: "unknown", values: waitForReady(tftypes.NewValue(tftypes.String, tftypes.UnknownValue))},
+		{name: "positive", values: waitForReady(tftypes.NewValue(tftypes.String, "5m"))},
+		{name: "zero", values: waitForReady(tftypes.NewValue(tftypes.String, "0s")), wantErr: "wait_for_ready timeout must be positive, got 0s", wantPath: path.Root("wait_for_ready").AtName("timeout")},
+		{name: "negative", values: waitForReady(tftypes.NewValue(tftypes.String, "-1m")), wantErr: "wait_for_ready timeout must be positive, got -1m0s", wantPath: path.Root("wait_for_ready").AtName("timeout")},
+		{name: "invalid", values: waitForReady(tftypes.NewValue(tftypes.String, "soon")), wantErr: "invalid wait_for_ready timeout", wantPath: path.Root("wait_for_ready").AtName("timeout")},
+		{name: "call timeout", values: map[string]tftypes.Value{"call_timeout": tftypes.NewValue(tftypes.String, "30s")}},
+		{name: "zero call timeout", values: map[string]tftypes.Value{"call_timeout": tftypes.NewValue(tftypes.String, "0s")}, wantErr: "call_timeout must be positive, got 0s", wantPath: path.Root("call_timeout")},
+		{name: "negative call timeout", values: map[string]tftypes.Value{"call_timeout": tftypes.NewValue(tftypes.String, "-5s")}, wantErr: "call_timeout must be positive, got -5s", wantPath: path.Root("call_timeout")},
+		{name: "invalid call timeout", values: map[string]tftypes.Value{"call_timeout": tftypes.NewValue(tftypes.String, "soon")}, wantErr: "invalid call_timeout", wantPath: path.Root("call_timeout")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var resp provider.ValidateConfigResponse
+			New("test")().(provider.ProviderWithValidateConfig).ValidateConfig(context.Background(), provider.ValidateConfigRequest{
+				Config: testProviderConfig(t, tt.values),
+			}, &resp)
+
+			if tt.wantErr == "" {
+				require.False(t, resp.Diagnostics.HasError(), "%v", resp.Diagnostics)
+				return
+			}
+			require.Len(t, resp.Diagnostics, 1)
+			require.Equal(t, "Invalid duration", resp.Diagnostics[0].Summary())
+			require.Equal(t, tt.wantPath, resp.Diagnostics[0].(diag.DiagnosticWithPath).Path())
+			require.Contains(t, resp.Diagnostics[0].Detail(), tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,227 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"strconv"
+	"strings"
+	"time"
+
+	"buf.build/gen/go/permifyco/permify/grpc/go/base/v1/basev1grpc"
+	permify_payload "buf.build/gen/go/permifyco/permify/protocolbuffers/go/base/v1"
+	permify_grpc "github.com/Permify/permify-go/grpc"
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	defaultMaxRetries  = 3
+	defaultBaseBackoff = 250 * time.Millisecond
+	defaultMaxBackoff  = 10 * time.Second
+)
+
+// defaultRetryableCodes are the codes a Permify server returns while it is
+// restarting or shedding load.
+var defaultRetryableCodes = []codes.Code{codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted}
+
+// statusCodeNames are the canonical names of the gRPC status codes, as
+// accepted in `retryable_codes`.
+var statusCodeNames = []string{
+	"CANCELLED", "UNKNOWN", "INVALID_ARGUMENT", "DEADLINE_EXCEEDED", "NOT_FOUND", "ALREADY_EXISTS",
+	"PERMISSION_DENIED", "RESOURCE_EXHAUSTED", "FAILED_PRECONDITION", "ABORTED", "OUT_OF_RANGE",
+	"UNIMPLEMENTED", "INTERNAL", "UNAVAILABLE", "DATA_LOSS", "UNAUTHENTICATED",
+}
+
+type RetryModel struct {
+	MaxRetries     types.Int64    `tfsdk:"max_retries"`
+	BaseBackoff    types.String   `tfsdk:"base_backoff"`
+	MaxBackoff     types.String   `tfsdk:"max_backoff"`
+	RetryableCodes []types.String `tfsdk:"retryable_codes"`
+}
+
+func retryBlock() schema.Block {
+	return schema.SingleNestedBlock{
+		MarkdownDescription: "Retry policy applied to every call to the Permify API.  Failed calls are retried with exponential " +
+			"backoff and jitter.  Creating a tenant is only retried after checking that the failed attempt did not create it.",
+		Attributes: map[string]schema.Attribute{
+			"max_retries": schema.Int64Attribute{
+				MarkdownDescription: fmt.Sprintf("Number of times a failed call is retried.  `0` disables retries.  Defaults to `%d`.", defaultMaxRetries),
+				Optional:            true,
+				Validators: []validator.Int64{
+					int64validator.AtLeast(0),
+				},
+			},
+			"base_backoff": schema.StringAttribute{
+				MarkdownDescription: fmt.Sprintf("Delay before the first retry, doubled on every further retry, as a Go duration such as `500ms`.  Defaults to `%s`.", defaultBaseBackoff),
+				Optional:            true,
+			},
+			"max_backoff": schema.StringAttribute{
+				MarkdownDescription: fmt.Sprintf("Upper bound of the delay between two retries, as a Go duration such as `30s`.  Defaults to `%s`.", defaultMaxBackoff),
+				Optional:            true,
+			},
+			"retryable_codes": schema.ListAttribute{
+				MarkdownDescription: "gRPC status codes that are retried, by their canonical name.  Defaults to `UNAVAILABLE`, `DEADLINE_EXCEEDED` and `RESOURCE_EXHAUSTED`.",
+				Optional:            true,
+				ElementType:         types.StringType,
+				Validators: []validator.List{
+					listvalidator.ValueStringsAre(stringvalidator.OneOf(statusCodeNames...)),
+				},
+			},
+		},
+	}
+}
+
+// retryPolicy decides which failed calls are retried and how long to wait
+// before each retry.
+type retryPolicy struct {
+	maxRetries  int
+	baseBackoff time.Duration
+	maxBackoff  time.Duration
+	retryable   map[codes.Code]bool
+}
+
+// policy returns the configured retry policy, with defaults for everything
+// that is not set.  It is safe to call on a nil model.
+func (m *RetryModel) policy() (retryPolicy, error) {
+	policy := retryPolicy{
+		maxRetries:  defaultMaxRetries,
+		baseBackoff: defaultBaseBackoff,
+		maxBackoff:  defaultMaxBackoff,
+		retryable:   map[codes.Code]bool{},
+	}
+	for _, code := range defaultRetryableCodes {
+		policy.retryable[code] = true
+	}
+	if m == nil {
+		return policy, nil
+	}
+
+	if !m.MaxRetries.IsNull() {
+		policy.maxRetries = int(m.MaxRetries.ValueInt64())
+	}
+	var err error
+	if !m.BaseBackoff.IsNull() {
+		if policy.baseBackoff, err = time.ParseDuration(m.BaseBackoff.ValueString()); err != nil {
+			return retryPolicy{}, fmt.Errorf("invalid base_backoff: %w", err)
+		}
+	}
+	if !m.MaxBackoff.IsNull() {
+		if policy.maxBackoff, err = time.ParseDuration(m.MaxBackoff.ValueString()); err != nil {
+			return retryPolicy{}, fmt.Errorf("invalid max_backoff: %w", err)
+		}
+	}
+	if policy.baseBackoff <= 0 || policy.maxBackoff < policy.baseBackoff {
+		return retryPolicy{}, fmt.Errorf("base_backoff must be positive and no larger than max_backoff, got %s and %s", policy.baseBackoff, policy.maxBackoff)
+	}
+	if m.RetryableCodes != nil {
+		policy.retryable = map[codes.Code]bool{}
+		for _, name := range m.RetryableCodes {
+			var code codes.Code
+			if err := code.UnmarshalJSON([]byte(strconv.Quote(strings.ToUpper(name.ValueString())))); err != nil {
+				return retryPolicy{}, fmt.Errorf("invalid retryable code %q", name.ValueString())
+			}
+			policy.retryable[code] = true
+		}
+	}
+
+	return policy, nil
+}
+
+// shouldRetry reports whether err is worth another attempt.  Calls are never
+// retried once their own context is done, even if the code is retryable.
+func (p retryPolicy) shouldRetry(ctx context.Context, err error) bool {
+	return err != nil && ctx.Err() == nil && p.retryable[status.Code(err)]
+}
+
+// backoff returns the delay before the given retry, counting from zero.  Half
+// of the delay is random so that clients failing together do not retry in step.
+func (p retryPolicy) backoff(retry int) time.Duration {
+	delay := p.maxBackoff
+	if retry < 32 && p.baseBackoff<<retry < p.maxBackoff {
+		delay = p.baseBackoff << retry
+	}
+	return delay/2 + rand.N(delay/2+1)
+}
+
+// unaryInterceptor applies the policy to unary calls.  Streams are not
+// retried, as a stream cannot be replayed once messages have been received.
+func (p retryPolicy) unaryInterceptor(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	err := invoker(ctx, method, req, reply, cc, opts...)
+	for retry := 0; retry < p.maxRetries && p.shouldRetry(ctx, err); retry++ {
+		delay := p.backoff(retry)
+		tflog.Warn(ctx, "Retrying Permify call", map[string]any{
+			"method":  method,
+			"retry":   retry + 1,
+			"code":    status.Code(err).String(),
+			"error":   err.Error(),
+			"backoff": delay.String(),
+		})
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+
+		if check, ok := existenceChecks[method]; ok {
+			// The existence check bypasses this interceptor.  When it finds that
+			// the call took effect, or fails itself, its result goes through the
+			// loop condition instead of sending the call again.
+			conn := invokerConn{ClientConnInterface: cc, cc: cc, invoker: invoker}
+			var done bool
+			if done, err = check(ctx, conn, req, reply); done || err != nil {
+				continue
+			}
+		}
+		err = invoker(ctx, method, req, reply, cc, opts...)
+	}
+	return err
+}
+
+// existenceCheck is run before retrying a call that is not idempotent.  It
+// reports done when the failed attempt took effect after all, having filled
+// reply as the call would have.
+type existenceCheck func(ctx context.Context, conn grpc.ClientConnInterface, req any, reply any) (done bool, err error)
+
+// existenceChecks holds the calls that must not simply be sent again.
+var existenceChecks = map[string]existenceCheck{
+	basev1grpc.Tenancy_Create_FullMethodName: tenantCreated,
+}
+
+func tenantCreated(ctx context.Context, conn grpc.ClientConnInterface, req any, reply any) (bool, error) {
+	create := req.(*permify_payload.TenantCreateRequest)
+	tenant, err := findTenant(ctx, &permify_grpc.Client{Tenancy: basev1grpc.NewTenancyClient(conn)}, create.Id)
+	if err != nil || tenant == nil {
+		return false, err
+	}
+	if tenant.Name != create.Name {
+		return true, status.Errorf(codes.AlreadyExists, "tenant %s already exists with name %q", tenant.Id, tenant.Name)
+	}
+
+	tflog.Info(ctx, "Permify tenant was created by an attempt that failed", map[string]any{"id": tenant.Id})
+	reply.(*permify_payload.TenantCreateResponse).Tenant = tenant
+	return true, nil
+}
+
+// invokerConn sends unary calls straight to the next invoker in the chain, so
+// that calls made from an interceptor do not pass through it again.
+type invokerConn struct {
+	grpc.ClientConnInterface
+	cc      *grpc.ClientConn
+	invoker grpc.UnaryInvoker
+}
+
+func (c invokerConn) Invoke(ctx context.Context, method string, args any, reply any, opts ...grpc.CallOption) error {
+	return c.invoker(ctx, method, args, reply, c.cc, opts...)
+}
@@ -0,0 +1,209 @@
+package provider
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"buf.build/gen/go/permifyco/permify/grpc/go/base/v1/basev1grpc"
+	permify_payload "buf.build/gen/go/permifyco/permify/protocolbuffers/go/base/v1"
+	permify_grpc "github.com/Permify/permify-go/grpc"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+func TestRetryPolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		model   *RetryModel
+		want    retryPolicy
+		wantErr bool
+	}{
+		{
+			name: "defaults",
+			want: retryPolicy{
+				maxRetries:  3,
+				baseBackoff: 250 * time.Millisecond,
+				maxBackoff:  10 * time.Second,
+				retryable:   map[codes.Code]bool{codes.Unavailable: true, codes.DeadlineExceeded: true, codes.ResourceExhausted: true},
+			},
+		},
+		{
+			name: "configured",
+			model: &RetryModel{
+				MaxRetries:     types.Int64Value(5),
+				BaseBackoff:    types.StringValue("1s"),
+				MaxBackoff:     types.StringValue("1m"),
+				RetryableCodes: []types.String{types.StringValue("UNAVAILABLE"), types.StringValue("ABORTED")},
+			},
+			want: retryPolicy{
+				maxRetries:  5,
+				baseBackoff: time.Second,
+				maxBackoff:  time.Minute,
+				retryable:   map[codes.Code]bool{codes.Unavailable: true, codes.Aborted: true},
+			},
+		},
+		{
+			name: "retries disabled",
+			model: &RetryModel{
+				MaxRetries:     types.Int64Value(0),
+				RetryableCodes: []types.String{},
+			},
+			want: retryPolicy{
+				maxRetries:  0,
+				baseBackoff: 250 * time.Millisecond,
+				maxBackoff:  10 * time.Second,
+				retryable:   map[codes.Code]bool{},
+			},
+		},
+		{
+			name:    "invalid duration",
+			model:   &RetryModel{BaseBackoff: types.StringValue("soon")},
+			wantErr: true,
+		},
+		{
+			name:    "base backoff above max backoff",
+			model:   &RetryModel{BaseBackoff: types.StringValue("1m"), MaxBackoff: types.StringValue("1s")},
+			wantErr: true,
+		},
+		{
+			name:    "unknown code",
+			model:   &RetryModel{RetryableCodes: []types.String{types.StringValue("FLAKY")}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy, err := tt.model.policy()
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, policy)
+		})
+	}
+}
+
+func TestRetryBackoff(t *testing.T) {
+	policy := retryPolicy{baseBackoff: 100 * time.Millisecond, maxBackoff: time.Second}
+
+	for retry, want := range []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond, time.Second, time.Second} {
+		delay := policy.backoff(retry)
+		require.GreaterOrEqual(t, delay, want/2)
+		require.LessOrEqual(t, delay, want)
+	}
+	require.LessOrEqual(t, policy.backoff(100), time.Second)
+}
+
+func newRetryTestClient(t *testing.T, fake *fakePermify, maxRetries int) *permify_grpc.Client {
+	policy := retryPolicy{
+		maxRetries:  maxRetries,
+		baseBackoff: time.Millisecond,
+		maxBackoff:  time.Millisecond,
+		retryable:   map[codes.Code]bool{codes.Unavailable: true},
+	}
+	client, err := permify_grpc.NewClient(permify_grpc.Config{Endpoint: fake.endpoint},
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithChainUnaryInterceptor(policy.unaryInterceptor),
+	)
+	require.NoError(t, err)
+	return client
+}
+
+func TestRetryInterceptor(t *testing.T) {
+	tests := []struct {
+		name      string
+		failures  int
+		code      codes.Code
+		wantCalls int
+		wantCode  codes.Code
+	}{
+		{
+			name:      "recovers from retryable failures",
+			failures:  2,
+			code:      codes.Unavailable,
+			wantCalls: 3,
+			wantCode:  codes.OK,
+		},
+		{
+			name:      "gives up after max retries",
+			failures:  10,
+			code:      codes.Unavailable,
+			wantCalls: 4,
+			wantCode:  codes.Unavailable,
+		},
+		{
+			name:      "does not retry other codes",
+			failures:  1,
+			code:      codes.PermissionDenied,
+			wantCalls: 1,
+			wantCode:  codes.PermissionDenied,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flaky := &flakyMethod{method: basev1grpc.Tenancy_List_FullMethodName, failures: tt.failures, code: tt.code}
+			fake := startFakePermify(t, flaky.serverOption())
+			client := newRetryTestClient(t, fake, 3)
+
+			err := listTenants(t, client)
+			require.Equal(t, tt.wantCode, status.Code(err))
+			require.Equal(t, tt.wantCalls, flaky.callCount())
+		})
+	}
+}
+
+func TestRetryStopsWhenContextIsDone(t *testing.T) {
+	flaky := &flakyMethod{method: basev1grpc.Tenancy_List_FullMethodName, failures: 10, code: codes.Unavailable}
+	fake := startFakePermify(t, flaky.serverOption())
+	client := newRetryTestClient(t, fake, 3)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := client.Tenancy.List(ctx, &permify_payload.TenantListRequest{PageSize: 1})
+	require.Error(t, err)
+	require.Zero(t, flaky.callCount())
+}
+
+func TestRetryTenantCreate(t *testing.T) {
+	t.Run("response lost after the tenant was created", func(t *testing.T) {
+		flaky := &flakyMethod{method: basev1grpc.Tenancy_Create_FullMethodName, failures: 1, code: codes.Unavailable, lost: true}
+		fake := startFakePermify(t, flaky.serverOption())
+		client := newRetryTestClient(t, fake, 3)
+
+		result, err := client.Tenancy.Create(context.Background(), &permify_payload.TenantCreateRequest{Id: "t1", Name: "Tenant 1"})
+		require.NoError(t, err)
+		require.Equal(t, "t1", result.Tenant.Id)
+		require.Equal(t, "Tenant 1", result.Tenant.Name)
+		require.Equal(t, 1, flaky.callCount())
+	})
+
+	t.Run("failed before the tenant was created", func(t *testing.T) {
+		flaky := &flakyMethod{method: basev1grpc.Tenancy_Create_FullMethodName, failures: 1, code: codes.Unavailable}
+		fake := startFakePermify(t, flaky.serverOption())
+		client := newRetryTestClient(t, fake, 3)
+
+		result, err := client.Tenancy.Create(context.Background(), &permify_payload.TenantCreateRequest{Id: "t1", Name: "Tenant 1"})
+		require.NoError(t, err)
+		require.Equal(t, "t1", result.Tenant.Id)
+		require.Equal(t, 2, flaky.callCount())
+	})
+
+	t.Run("tenant of another name already exists", func(t *testing.T) {
+		flaky := &flakyMethod{method: basev1grpc.Tenancy_Create_FullMethodName, failures: 1, code: codes.Unavailable}
+		fake := startFakePermify(t, flaky.serverOption())
+		fake.tenancy.tenants["t1"] = &permify_payload.Tenant{Id: "t1", Name: "Someone else's"}
+		client := newRetryTestClient(t, fake, 3)
+
+		_, err := client.Tenancy.Create(context.Background(), &permify_payload.TenantCreateRequest{Id: "t1", Name: "Tenant 1"})
+		require.Equal(t, codes.AlreadyExists, status.Code(err))
+		require.Equal(t, 1, flaky.callCount())
+	})
+}
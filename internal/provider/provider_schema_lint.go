@@ -0,0 +1,49 @@
+package provider
+
+import (
+	"github.com/hashicorp/terraform-plugin-framework-validators/mapvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+type SchemaLintModel struct {
+	Rules types.Map `tfsdk:"rules"`
+}
+
+func schemaLintBlock() schema.Block {
+	return schema.SingleNestedBlock{
+		MarkdownDescription: "Conventions every `permify_schema` is checked against when it is planned, without calling Permify.  " +
+			"Rules that are broken are reported as warnings or errors, depending on their severity.",
+		Attributes: map[string]schema.Attribute{
+			"rules": schema.MapAttribute{
+				MarkdownDescription: "The rules to enable, by name, with a severity of `warning` or `error`.  The rules are " +
+					"`snake_case_relations`, for relation names in snake_case; `entity_owner`, for an `owner` relation on every " +
+					"entity with relations; `no_unused_relations`, for relations that permissions use; and " +
+					"`no_undefined_relations`, for permissions that only refer to relations their entity declares.",
+				Optional:    true,
+				ElementType: types.StringType,
+				Validators: []validator.Map{
+					mapvalidator.KeysAre(stringvalidator.OneOf(lintRuleNames...)),
+					mapvalidator.ValueStringsAre(stringvalidator.OneOf(lintSeverityWarning, lintSeverityError)),
+				},
+			},
+		},
+	}
+}
+
+// rules returns the enabled rules, leaving out those whose severity is not
+// known yet.  It is safe to call on a nil model.
+func (m *SchemaLintModel) rules() schemaLintRules {
+	rules := schemaLintRules{}
+	if m == nil {
+		return rules
+	}
+	for name, severity := range m.Rules.Elements() {
+		if severity, ok := severity.(types.String); ok && !severity.IsUnknown() && !severity.IsNull() {
+			rules[name] = severity.ValueString()
+		}
+	}
+	return rules
+}
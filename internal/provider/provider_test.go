@@ -1,10 +1,28 @@
 package provider
 
 import (
+	"context"
 	"testing"
+	"time"
 
+	permify_payload "buf.build/gen/go/permifyco/permify/protocolbuffers/go/base/v1"
+	permify_grpc "github.com/Permify/permify-go/grpc"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/provider"
+	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
 	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	fwresource "github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 // testAccProtoV6ProviderFactories are used to instantiate a provider during
@@ -20,3 +38,402 @@ func testAccPreCheck(t *testing.T) {
 	// about the appropriate environment variables being set are common to see in a pre-check
 	// function.
 }
+
+func TestTokenCredentials(t *testing.T) {
+	tokens := startFakeTokenEndpoint(t, 3600)
+
+	tests := []struct {
+		name          string
+		authorization string
+		model         PermifyProviderModel
+		wantCode      codes.Code
+	}{
+		{
+			name:          "static token",
+			authorization: "pre-shared-key",
+			model:         PermifyProviderModel{Token: types.StringValue("pre-shared-key")},
+		},
+		{
+			name:          "oauth2",
+			authorization: "Bearer token-1",
+			model: PermifyProviderModel{OAuth2: &OAuth2Model{
+				TokenURL:     types.StringValue(tokens.URL),
+				ClientID:     types.StringValue("terraform"),
+				ClientSecret: types.StringValue("s3cret"),
+			}},
+		},
+		{
+			name:          "wrong token",
+			authorization: "pre-shared-key",
+			model:         PermifyProviderModel{Token: types.StringValue("guess")},
+			wantCode:      codes.Unauthenticated,
+		},
+		{
+			name:          "no credentials",
+			authorization: "pre-shared-key",
+			model:         PermifyProviderModel{},
+			wantCode:      codes.Unauthenticated,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := startFakePermify(t, requireAuthorization(tt.authorization)...)
+			options, err := getOptions(tt.model)
+			require.NoError(t, err)
+			client, err := permify_grpc.NewClient(permify_grpc.Config{Endpoint: fake.endpoint}, options...)
+			require.NoError(t, err)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			_, err = client.Tenancy.List(ctx, &permify_payload.TenantListRequest{PageSize: 1})
+			require.Equal(t, tt.wantCode, status.Code(err), "unary call")
+
+			stream, err := client.Watch.Watch(ctx, &permify_payload.WatchRequest{TenantId: "t1"})
+			require.NoError(t, err)
+			_, err = stream.Recv()
+			require.Equal(t, tt.wantCode, status.Code(err), "streaming call")
+		})
+	}
+}
+
+func TestHeadersAndUserAgent(t *testing.T) {
+	t.Setenv(envProfile, "")
+	fake := startFakePermify(t)
+
+	p := New("1.2.3")()
+	var resp provider.ConfigureResponse
+	p.Configure(context.Background(), provider.ConfigureRequest{
+		TerraformVersion: "1.9.5",
+		Config: testProviderConfig(t, map[string]tftypes.Value{
+			"endpoint": tftypes.NewValue(tftypes.String, fake.endpoint),
+			"headers": tftypes.NewValue(tftypes.Map{ElementType: tftypes.String}, map[string]tftypes.Value{
+				"X-Workspace": tftypes.NewValue(tftypes.String, "production"),
+				"x-route":     tftypes.NewValue(tftypes.String, "permify-blue"),
+			}),
+		}),
+	}, &resp)
+	require.False(t, resp.Diagnostics.HasError(), "%v", resp.Diagnostics)
+	client := resp.ResourceData.(*providerData).client
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err := client.Tenancy.List(ctx, &permify_payload.TenantListRequest{PageSize: 1})
+	require.NoError(t, err)
+	stream, err := client.Watch.Watch(ctx, &permify_payload.WatchRequest{TenantId: "t1"})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	require.NoError(t, err)
+
+	received := fake.received()
+	require.Len(t, received, 2)
+	for _, md := range received {
+		require.Equal(t, []string{"production"}, md.Get("x-workspace"))
+		require.Equal(t, []string{"permify-blue"}, md.Get("x-route"))
+		require.Len(t, md.Get("user-agent"), 1)
+		require.Contains(t, md.Get("user-agent")[0], "Terraform/1.9.5 (+https://www.terraform.io) terraform-provider-permify/1.2.3")
+	}
+}
+
+func TestHeadersValidation(t *testing.T) {
+	ctx := context.Background()
+	var schemaResp provider.SchemaResponse
+	New("test")().Schema(ctx, provider.SchemaRequest{}, &schemaResp)
+	headers := schemaResp.Schema.Attributes["headers"].(schema.MapAttribute)
+
+	for name, wantErr := range map[string]bool{
+		"x-workspace":   false,
+		"X-Trace.Id_2":  false,
+		"Authorization": true,
+		"user-agent":    true,
+		"x workspace":   true,
+	} {
+		t.Run(name, func(t *testing.T) {
+			req := validator.MapRequest{
+				Path:        path.Root("headers"),
+				ConfigValue: types.MapValueMust(types.StringType, map[string]attr.Value{name: types.StringValue("value")}),
+			}
+			var diags diag.Diagnostics
+			for _, v := range headers.Validators {
+				var resp validator.MapResponse
+				v.ValidateMap(ctx, req, &resp)
+				diags.Append(resp.Diagnostics...)
+			}
+			require.Equal(t, wantErr, diags.HasError(), "%v", diags)
+		})
+	}
+}
+
+// testProviderConfig builds a provider configuration from the given attribute
+// values, leaving every other attribute and block null.
+func testProviderConfig(t *testing.T, values map[string]tftypes.Value) tfsdk.Config {
+	ctx := context.Background()
+	var schemaResp provider.SchemaResponse
+	New("test")().Schema(ctx, provider.SchemaRequest{}, &schemaResp)
+	require.False(t, schemaResp.Diagnostics.HasError())
+
+	objectType, ok := schemaResp.Schema.Type().TerraformType(ctx).(tftypes.Object)
+	require.True(t, ok)
+	attributes := make(map[string]tftypes.Value, len(objectType.AttributeTypes))
+	for name, attributeType := range objectType.AttributeTypes {
+		if value, ok := values[name]; ok {
+			attributes[name] = value
+		} else {
+			attributes[name] = tftypes.NewValue(attributeType, nil)
+		}
+	}
+
+	return tfsdk.Config{
+		Schema: schemaResp.Schema,
+		Raw:    tftypes.NewValue(objectType, attributes),
+	}
+}
+
+func TestConfigureBuildsClient(t *testing.T) {
+	tests := []struct {
+		name     string
+		values   map[string]tftypes.Value
+		wantLint schemaLintRules
+	}{
+		{
+			name:     "default endpoint",
+			wantLint: schemaLintRules{},
+		},
+		{
+			name: "configured endpoint",
+			values: map[string]tftypes.Value{
+				"endpoint": tftypes.NewValue(tftypes.String, "permify.example.com:3478"),
+			},
+			wantLint: schemaLintRules{},
+		},
+		{
+			name: "schema lint",
+			values: map[string]tftypes.Value{
+				"schema_lint": tftypes.NewValue(
+					tftypes.Object{AttributeTypes: map[string]tftypes.Type{"rules": tftypes.Map{ElementType: tftypes.String}}},
+					map[string]tftypes.Value{
+						"rules": tftypes.NewValue(tftypes.Map{ElementType: tftypes.String}, map[string]tftypes.Value{
+							"entity_owner":         tftypes.NewValue(tftypes.String, "error"),
+							"snake_case_relations": tftypes.NewValue(tftypes.String, "warning"),
+						}),
+					},
+				),
+			},
+			wantLint: schemaLintRules{"entity_owner": lintSeverityError, "snake_case_relations": lintSeverityWarning},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(envEndpoint, "")
+			t.Setenv(envProfile, "")
+
+			var resp provider.ConfigureResponse
+			New("test")().Configure(context.Background(), provider.ConfigureRequest{
+				Config: testProviderConfig(t, tt.values),
+			}, &resp)
+
+			require.False(t, resp.Diagnostics.HasError(), "%v", resp.Diagnostics)
+			data, ok := resp.ResourceData.(*providerData)
+			require.True(t, ok)
+			require.NotNil(t, data.client)
+			require.False(t, data.configUnknown)
+			require.Equal(t, tt.wantLint, data.schemaLint)
+			require.Equal(t, resp.ResourceData, resp.DataSourceData)
+		})
+	}
+}
+
+func TestConfigureWithUnknownValues(t *testing.T) {
+	values := map[string]tftypes.Value{
+		"endpoint": tftypes.NewValue(tftypes.String, tftypes.UnknownValue),
+	}
+
+	t.Run("deferral allowed", func(t *testing.T) {
+		var resp provider.ConfigureResponse
+		New("test")().Configure(context.Background(), provider.ConfigureRequest{
+			Config:             testProviderConfig(t, values),
+			ClientCapabilities: provider.ConfigureProviderClientCapabilities{DeferralAllowed: true},
+		}, &resp)
+
+		require.False(t, resp.Diagnostics.HasError(), "%v", resp.Diagnostics)
+		require.NotNil(t, resp.Deferred)
+		require.Equal(t, provider.DeferredReasonProviderConfigUnknown, resp.Deferred.Reason)
+		require.Nil(t, resp.ResourceData)
+	})
+
+	t.Run("deferral not supported", func(t *testing.T) {
+		var resp provider.ConfigureResponse
+		New("test")().Configure(context.Background(), provider.ConfigureRequest{
+			Config: testProviderConfig(t, values),
+		}, &resp)
+
+		require.False(t, resp.Diagnostics.HasError(), "%v", resp.Diagnostics)
+		require.Nil(t, resp.Deferred)
+		data, ok := resp.ResourceData.(*providerData)
+		require.True(t, ok)
+		require.True(t, data.configUnknown)
+		require.Nil(t, data.client)
+		require.Equal(t, resp.ResourceData, resp.DataSourceData)
+		require.Empty(t, data.schemaLint)
+	})
+
+	t.Run("schema lint is carried", func(t *testing.T) {
+		rulesType := tftypes.Map{ElementType: tftypes.String}
+		var resp provider.ConfigureResponse
+		New("test")().Configure(context.Background(), provider.ConfigureRequest{
+			Config: testProviderConfig(t, map[string]tftypes.Value{
+				"endpoint": tftypes.NewValue(tftypes.String, tftypes.UnknownValue),
+				"schema_lint": tftypes.NewValue(tftypes.Object{AttributeTypes: map[string]tftypes.Type{"rules": rulesType}}, map[string]tftypes.Value{
+					"rules": tftypes.NewValue(rulesType, map[string]tftypes.Value{
+						"entity_owner":         tftypes.NewValue(tftypes.String, lintSeverityError),
+						"snake_case_relations": tftypes.NewValue(tftypes.String, tftypes.UnknownValue),
+					}),
+				}),
+			}),
+		}, &resp)
+
+		require.False(t, resp.Diagnostics.HasError(), "%v", resp.Diagnostics)
+		data, ok := resp.ResourceData.(*providerData)
+		require.True(t, ok)
+		require.True(t, data.configUnknown)
+		require.Equal(t, schemaLintRules{"entity_owner": lintSeverityError}, data.schemaLint)
+	})
+}
+
+func TestReadWithUnknownConfig(t *testing.T) {
+	ctx := context.Background()
+	pending := &providerData{configUnknown: true}
+
+	for name, r := range map[string]fwresource.Resource{
+		"tenant":  NewTenantResource(),
+		"schema":  NewSchemaResource(),
+		"bundles": NewBundlesResource(),
+	} {
+		t.Run(name, func(t *testing.T) {
+			r.(fwresource.ResourceWithConfigure).Configure(ctx, fwresource.ConfigureRequest{ProviderData: pending}, &fwresource.ConfigureResponse{})
+
+			var resp fwresource.ReadResponse
+			r.Read(ctx, fwresource.ReadRequest{}, &resp)
+			require.False(t, resp.Diagnostics.HasError(), "%v", resp.Diagnostics)
+		})
+	}
+
+	t.Run("tenant data source", func(t *testing.T) {
+		d := NewTenantDataSource()
+		var configureResp datasource.ConfigureResponse
+		d.(datasource.DataSourceWithConfigure).Configure(ctx, datasource.ConfigureRequest{ProviderData: pending}, &configureResp)
+		require.False(t, configureResp.Diagnostics.HasError(), "%v", configureResp.Diagnostics)
+
+		var resp datasource.ReadResponse
+		d.Read(ctx, datasource.ReadRequest{}, &resp)
+		require.Contains(t, resp.Diagnostics.Errors(), configUnknownDiagnostic())
+	})
+}
+
+func TestUnconfiguredClient(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		call func() diag.Diagnostics
+	}{
+		{
+			name: "tenant create",
+			call: func() diag.Diagnostics {
+				var resp fwresource.CreateResponse
+				NewTenantResource().Create(ctx, fwresource.CreateRequest{}, &resp)
+				return resp.Diagnostics
+			},
+		},
+		{
+			name: "tenant read",
+			call: func() diag.Diagnostics {
+				var resp fwresource.ReadResponse
+				NewTenantResource().Read(ctx, fwresource.ReadRequest{}, &resp)
+				return resp.Diagnostics
+			},
+		},
+		{
+			name: "tenant delete",
+			call: func() diag.Diagnostics {
+				var resp fwresource.DeleteResponse
+				NewTenantResource().Delete(ctx, fwresource.DeleteRequest{}, &resp)
+				return resp.Diagnostics
+			},
+		},
+		{
+			name: "schema create",
+			call: func() diag.Diagnostics {
+				var resp fwresource.CreateResponse
+				NewSchemaResource().Create(ctx, fwresource.CreateRequest{}, &resp)
+				return resp.Diagnostics
+			},
+		},
+		{
+			name: "schema read",
+			call: func() diag.Diagnostics {
+				var resp fwresource.ReadResponse
+				NewSchemaResource().Read(ctx, fwresource.ReadRequest{}, &resp)
+				return resp.Diagnostics
+			},
+		},
+		{
+			name: "schema update",
+			call: func() diag.Diagnostics {
+				var resp fwresource.UpdateResponse
+				NewSchemaResource().Update(ctx, fwresource.UpdateRequest{}, &resp)
+				return resp.Diagnostics
+			},
+		},
+		{
+			name: "bundles create",
+			call: func() diag.Diagnostics {
+				var resp fwresource.CreateResponse
+				NewBundlesResource().Create(ctx, fwresource.CreateRequest{}, &resp)
+				return resp.Diagnostics
+			},
+		},
+		{
+			name: "bundles read",
+			call: func() diag.Diagnostics {
+				var resp fwresource.ReadResponse
+				NewBundlesResource().Read(ctx, fwresource.ReadRequest{}, &resp)
+				return resp.Diagnostics
+			},
+		},
+		{
+			name: "bundles update",
+			call: func() diag.Diagnostics {
+				var resp fwresource.UpdateResponse
+				NewBundlesResource().Update(ctx, fwresource.UpdateRequest{}, &resp)
+				return resp.Diagnostics
+			},
+		},
+		{
+			name: "bundles delete",
+			call: func() diag.Diagnostics {
+				var resp fwresource.DeleteResponse
+				NewBundlesResource().Delete(ctx, fwresource.DeleteRequest{}, &resp)
+				return resp.Diagnostics
+			},
+		},
+		{
+			name: "tenant data source read",
+			call: func() diag.Diagnostics {
+				var resp datasource.ReadResponse
+				NewTenantDataSource().Read(ctx, datasource.ReadRequest{}, &resp)
+				return resp.Diagnostics
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diags := tt.call()
+			require.True(t, diags.HasError())
+			require.Contains(t, diags.Errors(), clientNotConfiguredDiagnostic())
+		})
+	}
+}
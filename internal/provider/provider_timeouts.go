@@ -0,0 +1,71 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// defaultCallTimeout bounds every attempt of a call to the Permify API when
+// `call_timeout` is not set.
+const defaultCallTimeout = 30 * time.Second
+
+// defaultOperationTimeout is used for every operation of a resource whose
+// `timeouts` block does not set one.  It leaves room for the retries of
+// several calls.
+const defaultOperationTimeout = 5 * time.Minute
+
+// callTimeout parses the `call_timeout` setting.
+func callTimeout(value string) (time.Duration, error) {
+	if value == "" {
+		return defaultCallTimeout, nil
+	}
+	timeout, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid call_timeout: %w", err)
+	}
+	if timeout <= 0 {
+		return 0, fmt.Errorf("call_timeout must be positive, got %s", timeout)
+	}
+	return timeout, nil
+}
+
+// callTimeoutInterceptor gives every attempt of a unary call a deadline of at
+// most timeout.  It sits inside the retry interceptor, so an attempt that hangs
+// is retried rather than holding up the whole operation.
+func callTimeoutInterceptor(timeout time.Duration) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		callCtx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+
+		err := invoker(callCtx, method, req, reply, cc, opts...)
+		if errors.Is(callCtx.Err(), context.DeadlineExceeded) && ctx.Err() == nil {
+			return status.Errorf(codes.DeadlineExceeded, "no response from Permify within the call timeout of %s", timeout)
+		}
+		return err
+	}
+}
+
+// operationErrorDiagnostic reports a failed call made by a resource operation.
+// When the operation ran out of time the diagnostic says so and points at the
+// setting that controls it, instead of showing a bare gRPC status.
+func operationErrorDiagnostic(ctx context.Context, summary string, operation string, timeout time.Duration, err error) diag.Diagnostic {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return diag.NewErrorDiagnostic(summary, fmt.Sprintf(
+			"The %s did not finish within its timeout of %s.  Check that Permify is reachable and healthy, "+
+				"or raise `%s` in the resource's `timeouts` block.\n\nLast error: %s",
+			operation, timeout, operation, err))
+	}
+	if status.Code(err) == codes.DeadlineExceeded {
+		return diag.NewErrorDiagnostic(summary, fmt.Sprintf(
+			"Permify did not answer in time.  Check that it is reachable and healthy, or raise the provider's `call_timeout`.\n\n%s",
+			status.Convert(err).Message()))
+	}
+	return diag.NewErrorDiagnostic(summary, err.Error())
+}
@@ -0,0 +1,117 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"buf.build/gen/go/permifyco/permify/grpc/go/base/v1/basev1grpc"
+	permify_grpc "github.com/Permify/permify-go/grpc"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+func TestCallTimeout(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{value: "", want: defaultCallTimeout},
+		{value: "5s", want: 5 * time.Second},
+		{value: "soon", wantErr: true},
+		{value: "0s", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			timeout, err := callTimeout(tt.value)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, timeout)
+		})
+	}
+}
+
+func TestCallTimeoutInterceptor(t *testing.T) {
+	newClient := func(t *testing.T, fake *fakePermify, interceptors ...grpc.UnaryClientInterceptor) *permify_grpc.Client {
+		client, err := permify_grpc.NewClient(permify_grpc.Config{Endpoint: fake.endpoint},
+			grpc.WithTransportCredentials(insecure.NewCredentials()),
+			grpc.WithChainUnaryInterceptor(interceptors...),
+		)
+		require.NoError(t, err)
+		return client
+	}
+
+	t.Run("hung call times out", func(t *testing.T) {
+		flaky := &flakyMethod{method: basev1grpc.Tenancy_List_FullMethodName, failures: 1, hang: true}
+		fake := startFakePermify(t, flaky.serverOption())
+		client := newClient(t, fake, callTimeoutInterceptor(50*time.Millisecond))
+
+		err := listTenants(t, client)
+		require.Equal(t, codes.DeadlineExceeded, status.Code(err))
+		require.Contains(t, err.Error(), "call timeout of 50ms")
+	})
+
+	t.Run("hung attempt is retried", func(t *testing.T) {
+		flaky := &flakyMethod{method: basev1grpc.Tenancy_List_FullMethodName, failures: 1, hang: true}
+		fake := startFakePermify(t, flaky.serverOption())
+		policy := retryPolicy{
+			maxRetries:  1,
+			baseBackoff: time.Millisecond,
+			maxBackoff:  time.Millisecond,
+			retryable:   map[codes.Code]bool{codes.DeadlineExceeded: true},
+		}
+		client := newClient(t, fake, policy.unaryInterceptor, callTimeoutInterceptor(50*time.Millisecond))
+
+		require.NoError(t, listTenants(t, client))
+		require.Equal(t, 2, flaky.callCount())
+	})
+}
+
+func TestOperationErrorDiagnostic(t *testing.T) {
+	expired, cancel := context.WithTimeout(context.Background(), time.Nanosecond)
+	defer cancel()
+	<-expired.Done()
+
+	tests := []struct {
+		name       string
+		ctx        context.Context
+		err        error
+		wantDetail string
+	}{
+		{
+			name:       "operation timed out",
+			ctx:        expired,
+			err:        status.Error(codes.DeadlineExceeded, "context deadline exceeded"),
+			wantDetail: "The create did not finish within its timeout of 2m0s",
+		},
+		{
+			name:       "call timed out",
+			ctx:        context.Background(),
+			err:        status.Error(codes.DeadlineExceeded, "no response from Permify within the call timeout of 30s"),
+			wantDetail: "raise the provider's `call_timeout`",
+		},
+		{
+			name:       "other failure",
+			ctx:        context.Background(),
+			err:        errors.New("boom"),
+			wantDetail: "boom",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diagnostic := operationErrorDiagnostic(tt.ctx, "Failed to create Permify Tenant", "create", 2*time.Minute, tt.err)
+			require.Equal(t, "Failed to create Permify Tenant", diagnostic.Summary())
+			require.Contains(t, diagnostic.Detail(), tt.wantDetail)
+		})
+	}
+}
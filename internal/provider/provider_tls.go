@@ -0,0 +1,140 @@
+package provider
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"google.golang.org/grpc/credentials"
+)
+
+type TLSModel struct {
+	Enabled               types.Bool   `tfsdk:"enabled"`
+	CACertificate         types.String `tfsdk:"ca_certificate"`
+	CACertificateFile     types.String `tfsdk:"ca_certificate_file"`
+	ClientCertificate     types.String `tfsdk:"client_certificate"`
+	ClientCertificateFile types.String `tfsdk:"client_certificate_file"`
+	ClientKey             types.String `tfsdk:"client_key"`
+	ClientKeyFile         types.String `tfsdk:"client_key_file"`
+	ServerName            types.String `tfsdk:"server_name"`
+	InsecureSkipVerify    types.Bool   `tfsdk:"insecure_skip_verify"`
+}
+
+func tlsBlock() schema.Block {
+	return schema.SingleNestedBlock{
+		MarkdownDescription: "Transport security for the gRPC connection.  When the block is omitted the provider dials without TLS.",
+		Attributes: map[string]schema.Attribute{
+			"enabled": schema.BoolAttribute{
+				MarkdownDescription: "Dial the endpoint over TLS.  Defaults to `true` when the `tls` block is present.",
+				Optional:            true,
+			},
+			"ca_certificate": schema.StringAttribute{
+				MarkdownDescription: "PEM-encoded CA bundle used to verify the server certificate.  Defaults to the system roots.",
+				Optional:            true,
+				Validators: []validator.String{
+					stringvalidator.ConflictsWith(path.MatchRelative().AtParent().AtName("ca_certificate_file")),
+				},
+			},
+			"ca_certificate_file": schema.StringAttribute{
+				MarkdownDescription: "Path to a PEM-encoded CA bundle used to verify the server certificate.",
+				Optional:            true,
+			},
+			"client_certificate": schema.StringAttribute{
+				MarkdownDescription: "PEM-encoded client certificate presented for mutual TLS.",
+				Optional:            true,
+				Validators: []validator.String{
+					stringvalidator.ConflictsWith(path.MatchRelative().AtParent().AtName("client_certificate_file")),
+				},
+			},
+			"client_certificate_file": schema.StringAttribute{
+				MarkdownDescription: "Path to a PEM-encoded client certificate presented for mutual TLS.",
+				Optional:            true,
+			},
+			"client_key": schema.StringAttribute{
+				MarkdownDescription: "PEM-encoded private key for `client_certificate`.",
+				Optional:            true,
+				Sensitive:           true,
+				Validators: []validator.String{
+					stringvalidator.ConflictsWith(path.MatchRelative().AtParent().AtName("client_key_file")),
+				},
+			},
+			"client_key_file": schema.StringAttribute{
+				MarkdownDescription: "Path to a PEM-encoded private key for the client certificate.",
+				Optional:            true,
+			},
+			"server_name": schema.StringAttribute{
+				MarkdownDescription: "Overrides the server name used to verify the server certificate.  Defaults to the host in `endpoint`.",
+				Optional:            true,
+			},
+			"insecure_skip_verify": schema.BoolAttribute{
+				MarkdownDescription: "Skip verification of the server certificate.  Only use this for local development.",
+				Optional:            true,
+			},
+		},
+	}
+}
+
+// enabled reports whether the connection should use TLS.  A present block
+// turns TLS on unless it is explicitly disabled.
+func (m *TLSModel) enabled() bool {
+	return m != nil && (m.Enabled.IsNull() || m.Enabled.ValueBool())
+}
+
+func (m *TLSModel) transportCredentials() (credentials.TransportCredentials, error) {
+	config := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         m.ServerName.ValueString(),
+		InsecureSkipVerify: m.InsecureSkipVerify.ValueBool(),
+	}
+
+	ca, err := readPEM(m.CACertificate, m.CACertificateFile)
+	if err != nil {
+		return nil, fmt.Errorf("reading CA certificate: %w", err)
+	}
+	if ca != nil {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("CA certificate does not contain any valid PEM certificates")
+		}
+		config.RootCAs = pool
+	}
+
+	cert, err := readPEM(m.ClientCertificate, m.ClientCertificateFile)
+	if err != nil {
+		return nil, fmt.Errorf("reading client certificate: %w", err)
+	}
+	key, err := readPEM(m.ClientKey, m.ClientKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("reading client key: %w", err)
+	}
+	if (cert == nil) != (key == nil) {
+		return nil, fmt.Errorf("client certificate and client key must be configured together")
+	}
+	if cert != nil {
+		pair, err := tls.X509KeyPair(cert, key)
+		if err != nil {
+			return nil, fmt.Errorf("loading client key pair: %w", err)
+		}
+		config.Certificates = []tls.Certificate{pair}
+	}
+
+	return credentials.NewTLS(config), nil
+}
+
+// readPEM returns the inline PEM value if set, otherwise the contents of the
+// file it points to.  It returns nil when neither is configured.
+func readPEM(inline types.String, file types.String) ([]byte, error) {
+	if inline.ValueString() != "" {
+		return []byte(inline.ValueString()), nil
+	}
+	if file.ValueString() != "" {
+		return os.ReadFile(file.ValueString())
+	}
+	return nil, nil
+}
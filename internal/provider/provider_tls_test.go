@@ -0,0 +1,177 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	permify_payload "buf.build/gen/go/permifyco/permify/protocolbuffers/go/base/v1"
+	permify_grpc "github.com/Permify/permify-go/grpc"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+func TestGetOptionsTLS(t *testing.T) {
+	pki := newTestPKI(t)
+
+	tests := []struct {
+		name              string
+		requireClientCert bool
+		tls               *TLSModel
+		wantErr           bool
+	}{
+		{
+			name:              "mutual TLS with inline PEM",
+			requireClientCert: true,
+			tls: &TLSModel{
+				CACertificate:     types.StringValue(string(pki.caPEM)),
+				ClientCertificate: types.StringValue(string(pki.clientCertPEM)),
+				ClientKey:         types.StringValue(string(pki.clientKeyPEM)),
+				ServerName:        types.StringValue(testServerName),
+			},
+		},
+		{
+			name:              "mutual TLS without a client certificate",
+			requireClientCert: true,
+			tls: &TLSModel{
+				CACertificate: types.StringValue(string(pki.caPEM)),
+				ServerName:    types.StringValue(testServerName),
+			},
+			wantErr: true,
+		},
+		{
+			name: "unknown certificate authority",
+			tls: &TLSModel{
+				ServerName: types.StringValue(testServerName),
+			},
+			wantErr: true,
+		},
+		{
+			name: "server name mismatch",
+			tls: &TLSModel{
+				CACertificate: types.StringValue(string(pki.caPEM)),
+			},
+			wantErr: true,
+		},
+		{
+			name: "insecure skip verify",
+			tls: &TLSModel{
+				InsecureSkipVerify: types.BoolValue(true),
+			},
+		},
+		{
+			name: "disabled",
+			tls: &TLSModel{
+				Enabled:       types.BoolValue(false),
+				CACertificate: types.StringValue(string(pki.caPEM)),
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := startFakePermify(t, grpc.Creds(credentials.NewTLS(pki.serverConfig(t, tt.requireClientCert))))
+
+			options, err := getOptions(PermifyProviderModel{TLS: tt.tls})
+			require.NoError(t, err)
+			client, err := permify_grpc.NewClient(permify_grpc.Config{Endpoint: fake.endpoint}, options...)
+			require.NoError(t, err)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			_, err = client.Tenancy.List(ctx, &permify_payload.TenantListRequest{PageSize: 1})
+			if tt.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestGetOptionsTLSInvalid(t *testing.T) {
+	pki := newTestPKI(t)
+
+	tests := []struct {
+		name string
+		tls  *TLSModel
+	}{
+		{
+			name: "certificate without key",
+			tls: &TLSModel{
+				ClientCertificate: types.StringValue(string(pki.clientCertPEM)),
+			},
+		},
+		{
+			name: "CA bundle without certificates",
+			tls: &TLSModel{
+				CACertificate: types.StringValue("not a certificate"),
+			},
+		},
+		{
+			name: "missing CA file",
+			tls: &TLSModel{
+				CACertificateFile: types.StringValue(filepath.Join(t.TempDir(), "missing.pem")),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := getOptions(PermifyProviderModel{TLS: tt.tls})
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestAccProviderTLS(t *testing.T) {
+	pki := newTestPKI(t)
+	fake := startFakePermify(t, grpc.Creds(credentials.NewTLS(pki.serverConfig(t, true))))
+
+	dir := t.TempDir()
+	writeFile := func(name string, content []byte) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, content, 0o600))
+		return path
+	}
+	caFile := writeFile("ca.pem", pki.caPEM)
+	certFile := writeFile("client.pem", pki.clientCertPEM)
+	keyFile := writeFile("client-key.pem", pki.clientKeyPEM)
+
+	providerConfig := fmt.Sprintf(`
+	provider "permify" {
+		endpoint = %[1]q
+
+		tls {
+			ca_certificate_file     = %[2]q
+			client_certificate_file = %[3]q
+			client_key_file         = %[4]q
+			server_name             = %[5]q
+		}
+	}
+	`, fake.endpoint, caFile, certFile, keyFile, testServerName)
+
+	resource.Test(t, resource.TestCase{
+		IsUnitTest: true,
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccTenantResourceConfig(providerConfig, "tls", "secure"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("permify_tenant.test", "id", "tls"),
+					resource.TestCheckResourceAttr("permify_tenant.test", "name", "secure"),
+				),
+			},
+		},
+	})
+}
@@ -6,6 +6,7 @@ import (
 
 	permify_payload "buf.build/gen/go/permifyco/permify/protocolbuffers/go/base/v1"
 	permify_grpc "github.com/Permify/permify-go/grpc"
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
@@ -20,7 +21,8 @@ var _ resource.ResourceWithConfigure = &bundlesResource{}
 var _ resource.ResourceWithImportState = &bundlesResource{}
 
 type bundlesResource struct {
-	client *permify_grpc.Client
+	client        *permify_grpc.Client
+	configUnknown bool
 }
 
 func NewBundlesResource() resource.Resource {
@@ -32,12 +34,13 @@ func (r *bundlesResource) Configure(ctx context.Context, req resource.ConfigureR
 		return
 	}
 
-	client, ok := req.ProviderData.(*permify_grpc.Client)
+	data, ok := req.ProviderData.(*providerData)
 	if !ok {
 		tflog.Error(ctx, "Unable to prepare client")
 		return
 	}
-	r.client = client
+	r.client = data.client
+	r.configUnknown = data.configUnknown
 }
 
 func (r *bundlesResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
@@ -105,11 +108,24 @@ func (r *bundlesResource) Schema(ctx context.Context, req resource.SchemaRequest
 				},
 			},
 		},
+		Blocks: map[string]schema.Block{
+			"timeouts": timeouts.Block(ctx, timeouts.Opts{
+				Create: true,
+				Read:   true,
+				Update: true,
+				Delete: true,
+			}),
+		},
 	}
 }
 
 func (r *bundlesResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
 	tflog.Debug(ctx, "Preparing to create bundles resource")
+	if r.client == nil {
+		resp.Diagnostics.Append(clientNotConfiguredDiagnostic())
+		return
+	}
+
 	var data BundlesModel
 	// Read Terraform plan data into the model
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
@@ -118,9 +134,17 @@ func (r *bundlesResource) Create(ctx context.Context, req resource.CreateRequest
 		return
 	}
 
+	timeout, diags := data.Timeouts.Create(ctx, defaultOperationTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
 	_, err := r.client.Bundle.Write(ctx, data.ToWriteRequest())
 	if err != nil {
-		resp.Diagnostics.AddError("Failed to create Permify Bundles", err.Error())
+		resp.Diagnostics.Append(operationErrorDiagnostic(ctx, "Failed to create Permify Bundles", "create", timeout, err))
 		return
 	}
 
@@ -134,6 +158,15 @@ func (r *bundlesResource) Create(ctx context.Context, req resource.CreateRequest
 
 func (r *bundlesResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
 	tflog.Debug(ctx, "Preparing to read bundles resource")
+	if r.configUnknown {
+		tflog.Warn(ctx, "Permify provider configuration is not known yet, keeping the prior state")
+		return
+	}
+	if r.client == nil {
+		resp.Diagnostics.Append(clientNotConfiguredDiagnostic())
+		return
+	}
+
 	var data BundlesModel
 	// Read Terraform plan data into the model
 	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
@@ -142,6 +175,14 @@ func (r *bundlesResource) Read(ctx context.Context, req resource.ReadRequest, re
 		return
 	}
 
+	timeout, diags := data.Timeouts.Read(ctx, defaultOperationTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
 	// Create a map to store results by bundle name to preserve order
 	bundleResults := make(map[string]BundleModel)
 	var (
@@ -160,7 +201,7 @@ func (r *bundlesResource) Read(ctx context.Context, req resource.ReadRequest, re
 			defer mu.Unlock()
 
 			if err != nil {
-				resp.Diagnostics.AddError("Failed to read Permify Bundle", err.Error())
+				resp.Diagnostics.Append(operationErrorDiagnostic(ctx, "Failed to read Permify Bundle", "read", timeout, err))
 			} else {
 				bundleResults[bundle.Name.ValueString()] = FromBundleReadResponse(result)
 			}
@@ -190,6 +231,11 @@ func (r *bundlesResource) Read(ctx context.Context, req resource.ReadRequest, re
 
 func (r *bundlesResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
 	tflog.Debug(ctx, "Preparing to update bundles resource")
+	if r.client == nil {
+		resp.Diagnostics.Append(clientNotConfiguredDiagnostic())
+		return
+	}
+
 	var data BundlesModel
 	// Read Terraform plan data into the model
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
@@ -205,9 +251,17 @@ func (r *bundlesResource) Update(ctx context.Context, req resource.UpdateRequest
 		return
 	}
 
+	timeout, diags := data.Timeouts.Update(ctx, defaultOperationTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
 	_, err := r.client.Bundle.Write(ctx, data.ToWriteRequest())
 	if err != nil {
-		resp.Diagnostics.AddError("Failed to update Permify Bundles", err.Error())
+		resp.Diagnostics.Append(operationErrorDiagnostic(ctx, "Failed to update Permify Bundles", "update", timeout, err))
 		return
 	}
 
@@ -226,7 +280,7 @@ func (r *bundlesResource) Update(ctx context.Context, req resource.UpdateRequest
 				Name:     bundle.Name.ValueString(),
 			})
 			if err != nil {
-				resp.Diagnostics.AddError("Failed to delete Permify Bundle", err.Error())
+				resp.Diagnostics.Append(operationErrorDiagnostic(ctx, "Failed to delete Permify Bundle", "update", timeout, err))
 			} else {
 				removed = append(removed, bundle.Name.ValueString())
 			}
@@ -242,6 +296,11 @@ func (r *bundlesResource) Update(ctx context.Context, req resource.UpdateRequest
 
 func (r *bundlesResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
 	tflog.Debug(ctx, "Preparing to delete bundles resource")
+	if r.client == nil {
+		resp.Diagnostics.Append(clientNotConfiguredDiagnostic())
+		return
+	}
+
 	var data BundlesModel
 	// Read Terraform plan data into the model
 	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
@@ -250,6 +309,14 @@ func (r *bundlesResource) Delete(ctx context.Context, req resource.DeleteRequest
 		return
 	}
 
+	timeout, diags := data.Timeouts.Delete(ctx, defaultOperationTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
 	var (
 		mu sync.Mutex
 		wg sync.WaitGroup
@@ -267,7 +334,7 @@ func (r *bundlesResource) Delete(ctx context.Context, req resource.DeleteRequest
 			defer mu.Unlock()
 
 			if err != nil {
-				resp.Diagnostics.AddError("Failed to delete Permify Bundle", err.Error())
+				resp.Diagnostics.Append(operationErrorDiagnostic(ctx, "Failed to delete Permify Bundle", "delete", timeout, err))
 				bundles = append(bundles, bundle)
 			}
 		})
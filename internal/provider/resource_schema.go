@@ -2,23 +2,46 @@ package provider
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
 
 	permify_payload "buf.build/gen/go/permifyco/permify/protocolbuffers/go/base/v1"
 	permify_grpc "github.com/Permify/permify-go/grpc"
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 var _ resource.Resource = &schemaResource{}
 var _ resource.ResourceWithConfigure = &schemaResource{}
 var _ resource.ResourceWithImportState = &schemaResource{}
+var _ resource.ResourceWithModifyPlan = &schemaResource{}
+var _ resource.ResourceWithValidateConfig = &schemaResource{}
+
+// pinnedVersionKey is the private state key holding the schema version a
+// resource was imported at, which Read keeps until the resource writes a new
+// one.
+const pinnedVersionKey = "pinned_schema_version"
 
 type schemaResource struct {
-	client *permify_grpc.Client
+	client        *permify_grpc.Client
+	configUnknown bool
+	schemaLint    schemaLintRules
 }
 
 func NewSchemaResource() resource.Resource {
@@ -30,12 +53,14 @@ func (r *schemaResource) Configure(ctx context.Context, req resource.ConfigureRe
 		return
 	}
 
-	client, ok := req.ProviderData.(*permify_grpc.Client)
+	data, ok := req.ProviderData.(*providerData)
 	if !ok {
 		tflog.Error(ctx, "Unable to prepare client")
 		return
 	}
-	r.client = client
+	r.client = data.client
+	r.configUnknown = data.configUnknown
+	r.schemaLint = data.schemaLint
 }
 
 func (r *schemaResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
@@ -54,26 +79,104 @@ func (r *schemaResource) Schema(ctx context.Context, req resource.SchemaRequest,
 				},
 			},
 			"schema": schema.StringAttribute{
-				MarkdownDescription: "The complete schema for the tenant",
-				Required:            true,
+				MarkdownDescription: "The complete schema for the tenant.  Changes to whitespace, comments and the order of " +
+					"declarations are ignored, and other changes write a new version of the schema.  Exactly one of `schema` " +
+					"and `source_version` must be set, and with `source_version` this is the schema read from that version.",
+				Optional:   true,
+				Computed:   true,
+				CustomType: SchemaStringType{},
 				PlanModifiers: []planmodifier.String{
-					stringplanmodifier.RequiresReplace(),
+					keepEquivalentSchema{},
+				},
+				Validators: []validator.String{
+					stringvalidator.ExactlyOneOf(path.MatchRoot("source_version")),
+				},
+			},
+			"source_version": schema.StringAttribute{
+				MarkdownDescription: "An earlier version of the tenant's schema to write as its new version, such as the version " +
+					"to roll back to from `version_history` or the `permify_schema_versions` data source.  Changing it, or the " +
+					"schema changing outside of Terraform, writes the source version again.",
+				Optional: true,
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
 				},
 			},
 			"schema_version": schema.StringAttribute{
 				MarkdownDescription: "The version of the schema",
 				Computed:            true,
 			},
+			"previous_schema_version": schema.StringAttribute{
+				MarkdownDescription: "The version the schema had before its latest change, if any",
+				Computed:            true,
+			},
+			"version_history": schema.ListAttribute{
+				MarkdownDescription: "The versions written by this resource, oldest first",
+				Computed:            true,
+				ElementType:         types.StringType,
+			},
+			"destroy_behavior": schema.StringAttribute{
+				MarkdownDescription: "What destroying the resource does, as Permify cannot delete schemas.  `retain` leaves the " +
+					"schema active on the tenant and warns about it, `clear` writes a schema with a single empty `user` entity " +
+					"that grants nothing, and `fail` refuses to destroy the resource while the tenant exists, so that the schema " +
+					"only goes away with its tenant.  Terraform destroys a schema before its tenant, so with `fail` a plain " +
+					"`terraform destroy` of both is refused too: remove the schema with a `removed` block whose lifecycle sets " +
+					"`destroy = false`, in the same apply that removes the tenant.  Defaults to `retain`.",
+				Optional: true,
+				Computed: true,
+				Default:  stringdefault.StaticString(destroyBehaviorRetain),
+				Validators: []validator.String{
+					stringvalidator.OneOf(destroyBehaviorRetain, destroyBehaviorClear, destroyBehaviorFail),
+				},
+			},
+			"force": schema.BoolAttribute{
+				MarkdownDescription: "Write changes even when the tenant's schema has moved on from `schema_version` since " +
+					"Terraform last read it.  By default such a change fails, so that concurrent writers do not overwrite each " +
+					"other unnoticed.",
+				Optional: true,
+				Computed: true,
+				Default:  booldefault.StaticBool(false),
+			},
+			"allow_orphaned_data": schema.BoolAttribute{
+				MarkdownDescription: "Write changes that remove entities, relations or attributes still used by relationships " +
+					"or attributes in the tenant.  By default such a change fails and lists some of the data it would orphan.",
+				Optional: true,
+				Computed: true,
+				Default:  booldefault.StaticBool(false),
+			},
+			"wait_for_propagation": schema.StringAttribute{
+				MarkdownDescription: "After writing a schema version, read it back until it can be read, for at most this " +
+					"long, as a Go duration such as `30s`.  Set it when Permify runs several nodes, so that resources writing " +
+					"data with the new version do not reach a node that does not know it yet.  When the version cannot be read " +
+					"in time the write is kept in state, and the apply fails on an update but only warns on a create, so that " +
+					"the new resource is not tainted and replaced.",
+				Optional: true,
+			},
 			"id": schema.StringAttribute{
 				MarkdownDescription: "Unique identifier",
 				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
 			},
 		},
+		Blocks: map[string]schema.Block{
+			"timeouts": timeouts.Block(ctx, timeouts.Opts{
+				Create: true,
+				Read:   true,
+				Update: true,
+				Delete: true,
+			}),
+		},
 	}
 }
 
 func (r *schemaResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
 	tflog.Debug(ctx, "Preparing to create schema resource")
+	if r.client == nil {
+		resp.Diagnostics.Append(clientNotConfiguredDiagnostic())
+		return
+	}
+
 	var data SchemaModel
 	// Read Terraform plan data into the model
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
@@ -82,26 +185,54 @@ func (r *schemaResource) Create(ctx context.Context, req resource.CreateRequest,
 		return
 	}
 
+	timeout, diags := data.Timeouts.Create(ctx, defaultOperationTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	if !data.SourceVersion.IsNull() {
+		resp.Diagnostics.Append(r.readSource(ctx, &data, "create", timeout)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
 	result, err := r.client.Schema.Write(ctx, &permify_payload.SchemaWriteRequest{
 		TenantId: data.TenantID.ValueString(),
 		Schema:   data.Schema.ValueString(),
 	})
 	if err != nil {
-		resp.Diagnostics.AddError("Failed to create Permify Schema", err.Error())
+		resp.Diagnostics.Append(operationErrorDiagnostic(ctx, "Failed to create Permify Schema", "create", timeout, err))
 		return
 	}
 
 	data.ID = data.TenantID
-	data.SchemaVersion = types.StringValue(result.SchemaVersion)
+	resp.Diagnostics.Append(data.recordVersion(ctx, result.SchemaVersion, true)...)
 
 	// Save data into Terraform state
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	resp.Diagnostics.Append(r.waitForPropagation(ctx, data, result.SchemaVersion, true)...)
 
 	tflog.Debug(ctx, "Created Schema resource", map[string]any{"success": true})
 }
 
 func (r *schemaResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
 	tflog.Debug(ctx, "Preparing to read item resource")
+	if r.configUnknown {
+		tflog.Warn(ctx, "Permify provider configuration is not known yet, keeping the prior state")
+		return
+	}
+	if r.client == nil {
+		resp.Diagnostics.Append(clientNotConfiguredDiagnostic())
+		return
+	}
+
 	// Get current state
 	var state SchemaModel
 	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
@@ -109,25 +240,66 @@ func (r *schemaResource) Read(ctx context.Context, req resource.ReadRequest, res
 		return
 	}
 
-	schema, err := r.client.Schema.Read(ctx, &permify_payload.SchemaReadRequest{
+	timeout, diags := state.Timeouts.Read(ctx, defaultOperationTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	// Schema versions are immutable, so the content can only have changed if
+	// the tenant's latest version is no longer the one in state.
+	list, err := r.client.Schema.List(ctx, &permify_payload.SchemaListRequest{
 		TenantId: state.TenantID.ValueString(),
-		Metadata: &permify_payload.SchemaReadRequestMetadata{SchemaVersion: state.SchemaVersion.ValueString()},
+		PageSize: 1,
 	})
+	if status.Code(err) == codes.NotFound || (err == nil && list.Head == "") {
+		tflog.Warn(ctx, "Permify Schema not found, removing it from state", map[string]any{"tenant_id": state.TenantID.ValueString()})
+		resp.State.RemoveResource(ctx)
+		return
+	}
 	if err != nil {
-		resp.Diagnostics.AddError("Error reading Permify Schema", err.Error())
+		resp.Diagnostics.Append(operationErrorDiagnostic(ctx, "Error reading Permify Schema", "read", timeout, err))
+		return
 	}
 
-	// TODO: Let's make sure we only do this if we're sure the schema is not found, not on intermittent errors
-	if schema == nil {
-		resp.State.RemoveResource(ctx)
+	// A freshly imported schema has no text yet, and is read at the version it
+	// was imported with, if any.  A pinned import stays at that version until
+	// the resource writes a new one.
+	var pinned string
+	value, diags := req.Private.GetKey(ctx, pinnedVersionKey)
+	resp.Diagnostics.Append(diags...)
+	if len(value) > 0 {
+		if err := json.Unmarshal(value, &pinned); err != nil {
+			resp.Diagnostics.AddError("Error reading Permify Schema", fmt.Sprintf("Invalid pinned schema version in private state: %s", err))
+			return
+		}
 	}
+	imported := state.Schema.IsNull()
+	version := list.Head
+	if (imported || pinned == state.SchemaVersion.ValueString()) && state.SchemaVersion.ValueString() != "" {
+		version = state.SchemaVersion.ValueString()
+	}
+
+	if imported || version != state.SchemaVersion.ValueString() {
+		remote, err := readSchemaVersion(ctx, r.client, state.TenantID.ValueString(), version)
+		if err != nil {
+			resp.Diagnostics.Append(operationErrorDiagnostic(ctx, "Error reading Permify Schema", "read", timeout, err))
+			return
+		}
 
-	state = SchemaModel{
-		ID:            types.StringValue(state.TenantID.ValueString()),
-		TenantID:      types.StringValue(state.TenantID.ValueString()),
-		Schema:        types.StringValue(state.Schema.ValueString()),
-		SchemaVersion: types.StringValue(state.SchemaVersion.ValueString()),
+		if !imported {
+			tflog.Warn(ctx, "Permify Schema was changed outside of Terraform", map[string]any{
+				"tenant_id":      state.TenantID.ValueString(),
+				"state_version":  state.SchemaVersion.ValueString(),
+				"remote_version": version,
+			})
+		}
+		state.Schema = NewSchemaStringValue(remote.String())
+		resp.Diagnostics.Append(state.recordVersion(ctx, version, false)...)
 	}
+	state.ID = state.TenantID
 
 	// Set refreshed state
 	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
@@ -139,6 +311,11 @@ func (r *schemaResource) Read(ctx context.Context, req resource.ReadRequest, res
 
 func (r *schemaResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
 	tflog.Debug(ctx, "Preparing to update schema resource")
+	if r.client == nil {
+		resp.Diagnostics.Append(clientNotConfiguredDiagnostic())
+		return
+	}
+
 	var data SchemaModel
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
 
@@ -146,23 +323,444 @@ func (r *schemaResource) Update(ctx context.Context, req resource.UpdateRequest,
 		return
 	}
 
+	var state SchemaModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	data.SchemaVersion = state.SchemaVersion
+	data.PreviousSchemaVersion = state.PreviousSchemaVersion
+	data.VersionHistory = state.VersionHistory
+
+	// Changing only the timeouts must not write a new schema version.
+	if data.Schema.Equal(state.Schema) {
+		resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+		return
+	}
+
+	timeout, diags := data.Timeouts.Update(ctx, defaultOperationTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	if !data.Force.ValueBool() {
+		resp.Diagnostics.Append(r.checkHead(ctx, state, timeout)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+	if !data.SourceVersion.IsNull() {
+		resp.Diagnostics.Append(r.readSource(ctx, &data, "update", timeout)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+	if !data.AllowOrphanedData.ValueBool() {
+		resp.Diagnostics.Append(r.checkOrphanedData(ctx, state, data, timeout)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
 	result, err := r.client.Schema.Write(ctx, &permify_payload.SchemaWriteRequest{
 		TenantId: data.TenantID.ValueString(),
 		Schema:   data.Schema.ValueString(),
 	})
 	if err != nil {
-		resp.Diagnostics.AddError("Failed to update Permify Schema", err.Error())
+		resp.Diagnostics.Append(operationErrorDiagnostic(ctx, "Failed to update Permify Schema", "update", timeout, err))
+		return
+	}
+	resp.Diagnostics.Append(data.recordVersion(ctx, result.SchemaVersion, true)...)
+	if resp.Diagnostics.HasError() {
 		return
 	}
-	data.SchemaVersion = types.StringValue(result.SchemaVersion)
+	if resp.Private != nil {
+		resp.Diagnostics.Append(resp.Private.SetKey(ctx, pinnedVersionKey, nil)...)
+	}
 
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	resp.Diagnostics.Append(r.waitForPropagation(ctx, data, result.SchemaVersion, false)...)
 
 	tflog.Debug(ctx, "Updated Permify Schema resource", map[string]any{"success": true})
 }
 
+// waitForPropagation waits for the written version to become readable when
+// wait_for_propagation is set.  The version is already in state, so a version
+// that does not propagate in time fails an update without losing track of it.
+// A create only warns, as Terraform taints a resource whose create fails and
+// would replace it, clearing or refusing to destroy the schema just written.
+func (r *schemaResource) waitForPropagation(ctx context.Context, data SchemaModel, version string, create bool) diag.Diagnostics {
+	var diags diag.Diagnostics
+	timeout, err := data.propagationTimeout()
+	if err != nil {
+		diags.AddAttributeError(path.Root("wait_for_propagation"), "Invalid duration", err.Error())
+		return diags
+	}
+	if timeout == 0 {
+		return diags
+	}
+
+	tenantID := data.TenantID.ValueString()
+	if err := waitForSchemaVersion(ctx, r.client, tenantID, version, timeout, defaultPropagationInterval); err != nil {
+		summary := "Permify Schema version did not propagate"
+		detail := fmt.Sprintf("Schema version %s of tenant %s was written, but could not be read back within %s, so some Permify "+
+			"nodes may not know it yet.\n\nLast error: %s", version, tenantID, timeout, err)
+		if create {
+			diags.AddWarning(summary, detail)
+		} else {
+			diags.AddError(summary, detail)
+		}
+	}
+	return diags
+}
+
+// ValidateConfig checks the schema and wait_for_propagation without calling
+// Permify, so that `terraform validate` reports mistakes where they are.
+func (r *schemaResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var config SchemaModel
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("wait_for_propagation"), &config.WaitForPropagation)...)
+	if _, err := config.propagationTimeout(); err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("wait_for_propagation"), "Invalid duration", err.Error())
+	}
+
+	var schemaText SchemaStringValue
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("schema"), &schemaText)...)
+	if resp.Diagnostics.HasError() || schemaText.IsNull() || schemaText.IsUnknown() {
+		return
+	}
+
+	for _, err := range checkSchema(schemaText.ValueString()) {
+		detail := err.Error()
+		if snippet := dslSnippet(schemaText.ValueString(), err.Position); snippet != "" {
+			detail += "\n\n" + snippet
+		}
+		resp.Diagnostics.AddAttributeError(path.Root("schema"), "Invalid Permify Schema", detail)
+	}
+}
+
+// ModifyPlan keeps the computed versions when the schema is unchanged.  A
+// changed schema is written as a new version, which is only known after apply.
+// A schema taken from a source version is written again when the source
+// version changes or the schema has moved on from what was written.  Changes
+// to a known schema are summarized in a warning, for reviewers of the plan,
+// and every known schema is checked against the provider's lint rules.
+func (r *schemaResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var plan SchemaModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if req.State.Raw.IsNull() {
+		resp.Diagnostics.Append(schemaLintDiagnostics(r.schemaLint, plan.Schema)...)
+		return
+	}
+
+	var state SchemaModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !plan.SourceVersion.IsNull() {
+		if plan.SourceVersion.Equal(state.SourceVersion) && !state.drifted(ctx) {
+			plan.Schema = state.Schema
+		} else {
+			plan.Schema = NewSchemaStringUnknown()
+		}
+	}
+
+	if plan.Schema.Equal(state.Schema) {
+		plan.SchemaVersion = state.SchemaVersion
+		plan.PreviousSchemaVersion = state.PreviousSchemaVersion
+		plan.VersionHistory = state.VersionHistory
+	} else {
+		plan.SchemaVersion = types.StringUnknown()
+		plan.PreviousSchemaVersion = types.StringUnknown()
+		plan.VersionHistory = types.ListUnknown(types.StringType)
+		if !plan.Schema.IsUnknown() {
+			resp.Diagnostics.Append(schemaChangesDiagnostics(plan.TenantID.ValueString(), state.Schema, plan.Schema)...)
+		}
+	}
+	resp.Diagnostics.Append(schemaLintDiagnostics(r.schemaLint, plan.Schema)...)
+
+	resp.Diagnostics.Append(resp.Plan.Set(ctx, &plan)...)
+}
+
+// schemaLintDiagnostics reports where the schema breaks the provider's
+// `schema_lint` rules.  Provider configuration only reaches resources once
+// they are planned, so the rules cannot run in ValidateConfig.  Schemas that
+// are unknown or do not parse are not linted.
+func schemaLintDiagnostics(rules schemaLintRules, schemaText SchemaStringValue) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if len(rules) == 0 || schemaText.IsNull() || schemaText.IsUnknown() {
+		return diags
+	}
+	parsed, err := parseSchema(schemaText.ValueString())
+	if err != nil {
+		return diags
+	}
+
+	for _, finding := range rules.lint(parsed) {
+		summary := fmt.Sprintf("Permify Schema breaks lint rule %s", finding.Rule)
+		detail := finding.Err.Error()
+		if snippet := dslSnippet(schemaText.ValueString(), finding.Err.Position); snippet != "" {
+			detail += "\n\n" + snippet
+		}
+		if finding.Severity == lintSeverityError {
+			diags.AddAttributeError(path.Root("schema"), summary, detail)
+		} else {
+			diags.AddAttributeWarning(path.Root("schema"), summary, detail)
+		}
+	}
+	return diags
+}
+
+// schemaChangesDiagnostics warns about the declarations that change between
+// the two schemas.  Schemas that do not parse are left to ValidateConfig and
+// Permify to report.
+func schemaChangesDiagnostics(tenantID string, prior SchemaStringValue, planned SchemaStringValue) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	old, err := parseSchema(prior.ValueString())
+	if err != nil {
+		return diags
+	}
+	updated, err := parseSchema(planned.ValueString())
+	if err != nil {
+		return diags
+	}
+	old.sortDeclarations()
+	updated.sortDeclarations()
+
+	changes := schemaChanges(old, updated)
+	if len(changes) == 0 {
+		return diags
+	}
+	lines := make([]string, len(changes))
+	breaking := 0
+	for i, change := range changes {
+		lines[i] = change.String()
+		if change.Breaking {
+			breaking++
+		}
+	}
+	summary := "Permify Schema changes"
+	if breaking > 0 {
+		summary = "Permify Schema changes, some of them breaking"
+	}
+	diags.AddAttributeWarning(
+		path.Root("schema"),
+		summary,
+		fmt.Sprintf("Applying this plan changes the schema of tenant %s:\n\n  %s\n\n%d of the %d changes are breaking: "+
+			"they remove declarations or narrow them, which can orphan data or fail the permission checks of clients.",
+			tenantID, strings.Join(lines, "\n  "), breaking, len(changes)),
+	)
+	return diags
+}
+
+// checkHead fails when the tenant's latest schema version is not the one in
+// state, as another writer changed the schema after Terraform last read it.
+// Permify has no conditional writes, so a write can still slip in between the
+// check and the write that follows it.
+func (r *schemaResource) checkHead(ctx context.Context, state SchemaModel, timeout time.Duration) diag.Diagnostics {
+	var diags diag.Diagnostics
+	tenantID := state.TenantID.ValueString()
+
+	list, err := r.client.Schema.List(ctx, &permify_payload.SchemaListRequest{
+		TenantId: tenantID,
+		PageSize: 1,
+	})
+	if status.Code(err) != codes.NotFound && err != nil {
+		diags.Append(operationErrorDiagnostic(ctx, "Error reading Permify Schema", "update", timeout, err))
+		return diags
+	}
+	head := ""
+	if err == nil {
+		head = list.Head
+	}
+
+	if head != state.SchemaVersion.ValueString() {
+		tflog.Warn(ctx, "Permify Schema was changed concurrently", map[string]any{
+			"tenant_id":      tenantID,
+			"state_version":  state.SchemaVersion.ValueString(),
+			"remote_version": head,
+		})
+		diags.AddError(
+			"Conflicting Permify Schema write",
+			fmt.Sprintf("The latest schema version of tenant %s is %q, but Terraform last read version %q, so another "+
+				"writer changed the schema in the meantime.  Plan again to review the schema it wrote, or set force = true "+
+				"to overwrite it.", tenantID, head, state.SchemaVersion.ValueString()),
+		)
+	}
+	return diags
+}
+
+// checkOrphanedData fails when the change from state to data removes
+// entities, relations or attributes that relationships or attributes in the
+// tenant still use.
+func (r *schemaResource) checkOrphanedData(ctx context.Context, state SchemaModel, data SchemaModel, timeout time.Duration) diag.Diagnostics {
+	var diags diag.Diagnostics
+	tenantID := data.TenantID.ValueString()
+
+	old, err := parseSchema(state.Schema.ValueString())
+	if err != nil {
+		diags.AddError("Error reading Permify Schema", fmt.Sprintf("The schema in state cannot be parsed: %s", err))
+		return diags
+	}
+	updated, err := parseSchema(data.Schema.ValueString())
+	if err != nil {
+		diags.AddAttributeError(path.Root("schema"), "Invalid Permify Schema", err.Error())
+		return diags
+	}
+
+	var orphans []string
+	for _, removal := range schemaRemovals(old, updated) {
+		found, err := orphanedData(ctx, r.client, tenantID, removal)
+		if err != nil {
+			diags.Append(operationErrorDiagnostic(ctx, "Error reading Permify data", "update", timeout, err))
+			return diags
+		}
+		if len(found) > 0 {
+			orphans = append(orphans, fmt.Sprintf("%s, used by %s", removal, strings.Join(found, ", ")))
+		}
+	}
+	if len(orphans) > 0 {
+		diags.AddAttributeError(
+			path.Root("schema"),
+			"Permify Schema change would orphan data",
+			fmt.Sprintf("The new schema of tenant %s removes declarations that its data still uses, so that data would no "+
+				"longer grant anything:\n\n  - %s\n\nDelete the data first, or set allow_orphaned_data = true to write the "+
+				"schema anyway.", tenantID, strings.Join(orphans, "\n  - ")),
+		)
+	}
+	return diags
+}
+
+// readSource plans the schema of the source version for writing.
+func (r *schemaResource) readSource(ctx context.Context, data *SchemaModel, operation string, timeout time.Duration) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	source, err := readSchemaVersion(ctx, r.client, data.TenantID.ValueString(), data.SourceVersion.ValueString())
+	if status.Code(err) == codes.NotFound {
+		diags.AddAttributeError(
+			path.Root("source_version"),
+			"Permify Schema version not found",
+			fmt.Sprintf("Tenant %s has no schema version %s to write again.", data.TenantID.ValueString(), data.SourceVersion.ValueString()),
+		)
+		return diags
+	}
+	if err != nil {
+		diags.Append(operationErrorDiagnostic(ctx, "Error reading Permify Schema", operation, timeout, err))
+		return diags
+	}
+	tflog.Debug(ctx, "Writing an earlier Permify Schema version again", map[string]any{
+		"tenant_id":      data.TenantID.ValueString(),
+		"source_version": data.SourceVersion.ValueString(),
+	})
+	data.Schema = NewSchemaStringValue(source.String())
+	return diags
+}
+
 func (r *schemaResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	if r.client == nil {
+		resp.Diagnostics.Append(clientNotConfiguredDiagnostic())
+		return
+	}
+
+	var state SchemaModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	timeout, diags := state.Timeouts.Delete(ctx, defaultOperationTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	tenantID := state.TenantID.ValueString()
+	tflog.Debug(ctx, "Preparing to delete Permify Schema resource", map[string]any{
+		"tenant_id":        tenantID,
+		"destroy_behavior": state.DestroyBehavior.ValueString(),
+	})
+
+	switch state.DestroyBehavior.ValueString() {
+	case destroyBehaviorClear:
+		result, err := r.client.Schema.Write(ctx, &permify_payload.SchemaWriteRequest{
+			TenantId: tenantID,
+			Schema:   clearedSchema,
+		})
+		if err != nil {
+			resp.Diagnostics.Append(operationErrorDiagnostic(ctx, "Failed to clear Permify Schema", "delete", timeout, err))
+			return
+		}
+		tflog.Debug(ctx, "Cleared Permify Schema", map[string]any{"tenant_id": tenantID, "schema_version": result.SchemaVersion})
+	case destroyBehaviorFail:
+		tenant, err := findTenant(ctx, r.client, tenantID)
+		if err != nil {
+			resp.Diagnostics.Append(operationErrorDiagnostic(ctx, "Error reading Permify Tenant", "delete", timeout, err))
+			return
+		}
+		if tenant != nil {
+			resp.Diagnostics.AddError(
+				"Permify Schema cannot be destroyed",
+				fmt.Sprintf("The schema of tenant %s has destroy_behavior = \"fail\", and the tenant still exists.  Terraform "+
+					"destroys a schema before its tenant, so to destroy both, replace the schema resource with a `removed` block "+
+					"whose lifecycle sets `destroy = false` in the same apply that removes the tenant.  Otherwise set "+
+					"destroy_behavior to \"retain\" or \"clear\" and apply before destroying the schema.", tenantID),
+			)
+			return
+		}
+	default:
+		resp.Diagnostics.AddWarning(
+			"Permify Schema retained",
+			fmt.Sprintf("Permify cannot delete schemas, so the schema version %s remains active on tenant %s.  Set "+
+				"destroy_behavior to \"clear\" to replace it with an empty schema instead.", state.SchemaVersion.ValueString(), tenantID),
+		)
+	}
+
+	tflog.Debug(ctx, "Deleted Permify Schema resource", map[string]any{"success": true})
 }
 
+// ImportState accepts `<tenant_id>` to import the latest version of the
+// tenant's schema, or `<tenant_id>/<schema_version>` to import an earlier one.
+// Read then fills in the schema text, and keeps a pinned version on later
+// refreshes instead of moving it to the tenant's latest version.
 func (r *schemaResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	parts := strings.Split(req.ID, "/")
+	if len(parts) > 2 || slices.Contains(parts, "") {
+		resp.Diagnostics.AddError(
+			"Unexpected Import Identifier",
+			fmt.Sprintf("Expected an import identifier of the form <tenant_id> or <tenant_id>/<schema_version>, got %q.", req.ID),
+		)
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), parts[0])...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("tenant_id"), parts[0])...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("destroy_behavior"), destroyBehaviorRetain)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("force"), false)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("allow_orphaned_data"), false)...)
+	if len(parts) == 2 {
+		resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("schema_version"), parts[1])...)
+		if resp.Private != nil {
+			pinned, _ := json.Marshal(parts[1])
+			resp.Diagnostics.Append(resp.Private.SetKey(ctx, pinnedVersionKey, pinned)...)
+		}
+	}
 }
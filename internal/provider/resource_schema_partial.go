@@ -0,0 +1,417 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	permify_payload "buf.build/gen/go/permifyco/permify/protocolbuffers/go/base/v1"
+	permify_grpc "github.com/Permify/permify-go/grpc"
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+var _ resource.Resource = &schemaPartialResource{}
+var _ resource.ResourceWithConfigure = &schemaPartialResource{}
+var _ resource.ResourceWithModifyPlan = &schemaPartialResource{}
+var _ resource.ResourceWithValidateConfig = &schemaPartialResource{}
+
+type schemaPartialResource struct {
+	client        *permify_grpc.Client
+	configUnknown bool
+}
+
+func NewSchemaPartialResource() resource.Resource {
+	return &schemaPartialResource{}
+}
+
+func (r *schemaPartialResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	data, ok := req.ProviderData.(*providerData)
+	if !ok {
+		tflog.Error(ctx, "Unable to prepare client")
+		return
+	}
+	r.client = data.client
+	r.configUnknown = data.configUnknown
+}
+
+func (r *schemaPartialResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_schema_partial"
+}
+
+func (r *schemaPartialResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Part of a tenant's schema: some relations, attributes and permissions of entities that are " +
+			"declared by the schema already.  Several of these resources can manage different members of one schema, " +
+			"and report members that another one manages as conflicts.  Do not combine them with a `permify_schema` " +
+			"resource that declares the same members.",
+		Attributes: map[string]schema.Attribute{
+			"tenant_id": schema.StringAttribute{
+				MarkdownDescription: "The ID of the tenant the schema belongs to",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"schema": schema.StringAttribute{
+				MarkdownDescription: "Entity blocks holding the members managed by this resource, such as " +
+					"`entity document { relation owner @user }`.  Rules cannot be written partially.",
+				Required:   true,
+				CustomType: SchemaStringType{},
+				PlanModifiers: []planmodifier.String{
+					keepEquivalentSchema{},
+				},
+			},
+			"schema_version": schema.StringAttribute{
+				MarkdownDescription: "The version of the schema written by the latest change",
+				Computed:            true,
+			},
+			"id": schema.StringAttribute{
+				MarkdownDescription: "Unique identifier",
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"timeouts": timeouts.Block(ctx, timeouts.Opts{
+				Create: true,
+				Read:   true,
+				Update: true,
+				Delete: true,
+			}),
+		},
+	}
+}
+
+func (r *schemaPartialResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var schemaText SchemaStringValue
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("schema"), &schemaText)...)
+	if resp.Diagnostics.HasError() || schemaText.IsNull() || schemaText.IsUnknown() {
+		return
+	}
+
+	// The members may refer to anything in the rest of the schema, so only the
+	// syntax can be checked without Permify.
+	if _, err := parsePartialSchema(schemaText); err != nil {
+		detail := err.Error()
+		if dslErr, ok := err.(*dslError); ok {
+			if snippet := dslSnippet(schemaText.ValueString(), dslErr.Position); snippet != "" {
+				detail += "\n\n" + snippet
+			}
+		}
+		resp.Diagnostics.AddAttributeError(path.Root("schema"), "Invalid Permify Schema", detail)
+	}
+}
+
+// ModifyPlan keeps the schema version when the members are unchanged, and
+// reports conflicts with members managed elsewhere before anything is applied.
+func (r *schemaPartialResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var plan, state SchemaPartialModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if !req.State.Raw.IsNull() {
+		resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !req.State.Raw.IsNull() && plan.Schema.Equal(state.Schema) {
+		plan.SchemaVersion = state.SchemaVersion
+		resp.Diagnostics.Append(resp.Plan.Set(ctx, &plan)...)
+		return
+	}
+	if !req.State.Raw.IsNull() {
+		plan.SchemaVersion = types.StringUnknown()
+		resp.Diagnostics.Append(resp.Plan.Set(ctx, &plan)...)
+	}
+
+	// The tenant may not exist before the apply, in which case there is
+	// nothing to conflict with yet.
+	if r.client == nil || plan.TenantID.IsUnknown() || plan.Schema.IsUnknown() {
+		return
+	}
+	prior, planned, err := r.parseChange(state, plan)
+	if err != nil {
+		return
+	}
+	_, remote, err := readSchemaHead(ctx, r.client, plan.TenantID.ValueString())
+	if err != nil || remote == nil {
+		tflog.Debug(ctx, "Not checking the Permify Schema for conflicts", map[string]any{"tenant_id": plan.TenantID.ValueString()})
+		return
+	}
+	change := diffPartialSchemas(prior, planned, remote)
+	resp.Diagnostics.Append(partialConflictDiagnostics(plan.TenantID.ValueString(), change)...)
+}
+
+func (r *schemaPartialResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	tflog.Debug(ctx, "Preparing to create schema partial resource")
+	if r.client == nil {
+		resp.Diagnostics.Append(clientNotConfiguredDiagnostic())
+		return
+	}
+
+	var data SchemaPartialModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	timeout, diags := data.Timeouts.Create(ctx, defaultOperationTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	resp.Diagnostics.Append(r.write(ctx, SchemaPartialModel{}, &data, "create", timeout)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	data.ID = data.TenantID
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+
+	tflog.Debug(ctx, "Created Schema Partial resource", map[string]any{"success": true})
+}
+
+func (r *schemaPartialResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	tflog.Debug(ctx, "Preparing to read schema partial resource")
+	if r.configUnknown {
+		tflog.Warn(ctx, "Permify provider configuration is not known yet, keeping the prior state")
+		return
+	}
+	if r.client == nil {
+		resp.Diagnostics.Append(clientNotConfiguredDiagnostic())
+		return
+	}
+
+	var state SchemaPartialModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	timeout, diags := state.Timeouts.Read(ctx, defaultOperationTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	head, remote, err := readSchemaHead(ctx, r.client, state.TenantID.ValueString())
+	if err != nil {
+		resp.Diagnostics.Append(operationErrorDiagnostic(ctx, "Error reading Permify Schema", "read", timeout, err))
+		return
+	}
+	if remote == nil {
+		tflog.Warn(ctx, "Permify Schema not found, removing it from state", map[string]any{"tenant_id": state.TenantID.ValueString()})
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	prior, err := parsePartialSchema(state.Schema)
+	if err != nil {
+		resp.Diagnostics.AddError("Error reading Permify Schema", fmt.Sprintf("The schema in state cannot be parsed: %s", err))
+		return
+	}
+	// Members changed by others show up as a difference to the configuration.
+	state.Schema = NewSchemaStringValue(readPartialSchema(prior, remote).String())
+	state.ID = state.TenantID
+
+	if head != state.SchemaVersion.ValueString() {
+		tflog.Debug(ctx, "Permify Schema has changed since it was last written", map[string]any{
+			"tenant_id":      state.TenantID.ValueString(),
+			"state_version":  state.SchemaVersion.ValueString(),
+			"remote_version": head,
+		})
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	tflog.Debug(ctx, "Finished reading Permify Schema Partial resource", map[string]any{"success": true})
+}
+
+func (r *schemaPartialResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	tflog.Debug(ctx, "Preparing to update schema partial resource")
+	if r.client == nil {
+		resp.Diagnostics.Append(clientNotConfiguredDiagnostic())
+		return
+	}
+
+	var data, state SchemaPartialModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Changing only the timeouts must not write a new schema version.
+	if data.Schema.Equal(state.Schema) {
+		data.SchemaVersion = state.SchemaVersion
+		resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+		return
+	}
+
+	timeout, diags := data.Timeouts.Update(ctx, defaultOperationTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	resp.Diagnostics.Append(r.write(ctx, state, &data, "update", timeout)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+
+	tflog.Debug(ctx, "Updated Permify Schema Partial resource", map[string]any{"success": true})
+}
+
+func (r *schemaPartialResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	if r.client == nil {
+		resp.Diagnostics.Append(clientNotConfiguredDiagnostic())
+		return
+	}
+
+	var state SchemaPartialModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	timeout, diags := state.Timeouts.Delete(ctx, defaultOperationTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	tflog.Debug(ctx, "Preparing to delete Permify Schema Partial resource", map[string]any{"tenant_id": state.TenantID.ValueString()})
+
+	// Removing every managed member leaves the entities themselves, which
+	// belong to the rest of the schema.
+	planned := SchemaPartialModel{TenantID: state.TenantID, Schema: NewSchemaStringValue("")}
+	resp.Diagnostics.Append(r.write(ctx, state, &planned, "delete", timeout)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	tflog.Debug(ctx, "Deleted Permify Schema Partial resource", map[string]any{"success": true})
+}
+
+func (r *schemaPartialResource) parseChange(state SchemaPartialModel, plan SchemaPartialModel) (*dslSchema, *dslSchema, error) {
+	prior, err := parsePartialSchema(state.Schema)
+	if err != nil {
+		return nil, nil, fmt.Errorf("the schema in state cannot be parsed: %w", err)
+	}
+	planned, err := parsePartialSchema(plan.Schema)
+	if err != nil {
+		return nil, nil, err
+	}
+	return prior, planned, nil
+}
+
+// write changes the members managed in state to the ones planned, and
+// records the version it wrote in planned.  Permify applies the partials to
+// its latest version, so that writes made since the conflict check are kept,
+// although they are not checked for conflicts themselves.
+func (r *schemaPartialResource) write(ctx context.Context, state SchemaPartialModel, planned *SchemaPartialModel, operation string, timeout time.Duration) diag.Diagnostics {
+	var diags diag.Diagnostics
+	tenantID := planned.TenantID.ValueString()
+
+	prior, wanted, err := r.parseChange(state, *planned)
+	if err != nil {
+		diags.AddAttributeError(path.Root("schema"), "Invalid Permify Schema", err.Error())
+		return diags
+	}
+
+	head, remote, err := readSchemaHead(ctx, r.client, tenantID)
+	if err != nil {
+		diags.Append(operationErrorDiagnostic(ctx, "Error reading Permify Schema", operation, timeout, err))
+		return diags
+	}
+	if remote == nil {
+		if operation == "delete" {
+			return diags
+		}
+		diags.AddError(
+			"Permify Schema not found",
+			fmt.Sprintf("Tenant %s has no schema.  Partial schemas change the members of entities that a schema declares "+
+				"already, so write one with a permify_schema resource first.", tenantID),
+		)
+		return diags
+	}
+
+	change := diffPartialSchemas(prior, wanted, remote)
+	diags.Append(partialConflictDiagnostics(tenantID, change)...)
+	if diags.HasError() {
+		return diags
+	}
+
+	if len(change.partials) == 0 {
+		tflog.Debug(ctx, "Permify Schema already has the planned members", map[string]any{"tenant_id": tenantID})
+		planned.SchemaVersion = types.StringValue(head)
+		return diags
+	}
+
+	result, err := r.client.Schema.PartialWrite(ctx, &permify_payload.SchemaPartialWriteRequest{
+		TenantId: tenantID,
+		Metadata: &permify_payload.SchemaPartialWriteRequestMetadata{},
+		Partials: change.partials,
+	})
+	if err != nil {
+		diags.Append(operationErrorDiagnostic(ctx, "Failed to write Permify Schema partially", operation, timeout, err))
+		return diags
+	}
+	planned.SchemaVersion = types.StringValue(result.SchemaVersion)
+	return diags
+}
+
+func partialConflictDiagnostics(tenantID string, change schemaPartialChange) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if len(change.missing) > 0 {
+		diags.AddAttributeError(
+			path.Root("schema"),
+			"Permify Schema entities not found",
+			fmt.Sprintf("The schema of tenant %s does not declare %s.  Partial schemas can only change the members of "+
+				"entities that the schema declares already.", tenantID, strings.Join(change.missing, ", ")),
+		)
+	}
+	if len(change.conflicts) > 0 {
+		diags.AddAttributeError(
+			path.Root("schema"),
+			"Conflicting Permify Schema members",
+			fmt.Sprintf("The schema of tenant %s already has members that this resource does not manage:\n\n  - %s\n\n"+
+				"Another permify_schema_partial or permify_schema resource probably manages them.  Each member can only "+
+				"be managed by one resource.", tenantID, strings.Join(change.conflicts, "\n  - ")),
+		)
+	}
+	return diags
+}
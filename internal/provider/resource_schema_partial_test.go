@@ -0,0 +1,355 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sync"
+	"testing"
+
+	"buf.build/gen/go/permifyco/permify/grpc/go/base/v1/basev1grpc"
+	permify_payload "buf.build/gen/go/permifyco/permify/protocolbuffers/go/base/v1"
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	fwresource "github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+)
+
+func TestAccSchemaPartialResource(t *testing.T) {
+	providerConfig := initPermify(t)
+
+	resource.Test(t, resource.TestCase{
+		IsUnitTest: true,
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create two partials next to the base schema
+			{
+				Config: testAccSchemaPartialResourceConfig(providerConfig, `
+entity organization {
+    relation viewer @user
+    permission view = admin or viewer
+}
+`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("permify_schema_partial.viewers", "id", "partial-tenant"),
+					resource.TestCheckResourceAttrSet("permify_schema_partial.viewers", "schema_version"),
+					resource.TestCheckResourceAttrSet("permify_schema_partial.auditors", "schema_version"),
+				),
+			},
+			// Update one of them in place
+			{
+				Config: testAccSchemaPartialResourceConfig(providerConfig, `
+entity organization {
+    relation viewer @user @organization#member
+    permission view = admin or viewer
+}
+`),
+				Check: resource.TestCheckResourceAttrSet("permify_schema_partial.viewers", "schema_version"),
+			},
+			// A member of the other partial is a conflict
+			{
+				Config: testAccSchemaPartialResourceConfig(providerConfig, `
+entity organization {
+    relation auditor @user
+}
+`),
+				ExpectError: regexp.MustCompile(`Conflicting Permify Schema members`),
+			},
+		},
+	})
+}
+
+func testAccSchemaPartialResourceConfig(providerConfig string, viewers string) string {
+	return providerConfig + fmt.Sprintf(`
+resource "permify_tenant" "test" {
+  id = "partial-tenant"
+  name = "Partial Tenant"
+}
+
+resource "permify_schema" "base" {
+  tenant_id = permify_tenant.test.id
+  schema = <<EOF
+entity user {}
+
+entity organization {
+    relation admin @user
+    relation member @user
+}
+EOF
+
+  lifecycle {
+    ignore_changes = [schema]
+  }
+}
+
+resource "permify_schema_partial" "viewers" {
+  tenant_id = permify_schema.base.tenant_id
+  schema = %[1]q
+}
+
+resource "permify_schema_partial" "auditors" {
+  tenant_id = permify_schema.base.tenant_id
+  schema = <<EOF
+entity organization {
+    relation auditor @user @organization#admin
+}
+EOF
+
+  depends_on = [permify_schema_partial.viewers]
+}
+`, viewers)
+}
+
+const testPartialBaseSchema = `entity organization {
+    relation admin @user
+    relation member @user
+
+    permission view = admin
+}
+
+entity user {}
+`
+
+func TestDiffPartialSchemas(t *testing.T) {
+	parse := func(t *testing.T, text string) *dslSchema {
+		schema, err := parsePartialSchema(NewSchemaStringValue(text))
+		require.NoError(t, err)
+		return schema
+	}
+	remote := parse(t, `
+entity organization {
+    relation admin @user
+    relation member @user
+    relation viewer @user
+    attribute public boolean
+    permission view = admin
+}
+
+entity user {}
+`)
+
+	t.Run("create", func(t *testing.T) {
+		change := diffPartialSchemas(parse(t, ""), parse(t, `
+entity organization {
+    attribute tags string[]
+    permission edit = admin
+}
+`), remote)
+		require.Empty(t, change.conflicts)
+		require.Empty(t, change.missing)
+		require.Equal(t, map[string]*permify_payload.Partials{
+			"organization": {Write: []string{"permission edit = admin", "attribute tags string[]"}},
+		}, change.partials)
+	})
+
+	t.Run("update and delete", func(t *testing.T) {
+		change := diffPartialSchemas(parse(t, `
+entity organization {
+    relation viewer @user
+    attribute public boolean
+}
+`), parse(t, `
+entity organization {
+    relation viewer @user @organization#member
+}
+`), remote)
+		require.Empty(t, change.conflicts)
+		require.Equal(t, map[string]*permify_payload.Partials{
+			"organization": {
+				Update: []string{"relation viewer @organization#member @user"},
+				Delete: []string{"public"},
+			},
+		}, change.partials)
+	})
+
+	t.Run("conflicts", func(t *testing.T) {
+		change := diffPartialSchemas(parse(t, ""), parse(t, `
+entity organization {
+    permission view = admin or member
+}
+
+entity repository {
+    relation owner @user
+}
+`), remote)
+		require.Equal(t, []string{"view of entity organization is already declared as `permission view = admin`"}, change.conflicts)
+		require.Equal(t, []string{"repository"}, change.missing)
+	})
+
+	// Destroying the partial would delete a member that another resource
+	// still declares.
+	t.Run("identical members conflict", func(t *testing.T) {
+		change := diffPartialSchemas(parse(t, ""), parse(t, `
+entity organization {
+    relation viewer @user
+}
+`), remote)
+		require.Equal(t, []string{"viewer of entity organization is already declared as `relation viewer @user`"}, change.conflicts)
+		require.Empty(t, change.partials)
+	})
+
+	t.Run("owned members do not conflict", func(t *testing.T) {
+		viewers := parse(t, `
+entity organization {
+    relation viewer @user
+}
+`)
+		change := diffPartialSchemas(viewers, viewers, remote)
+		require.Empty(t, change.conflicts)
+		require.Empty(t, change.partials)
+	})
+}
+
+// testSchemaPartialState builds the state of a permify_schema_partial
+// resource that wrote the schema with the given version.
+func testSchemaPartialState(t *testing.T, r fwresource.Resource, tenantID string, schemaText string, version string) tfsdk.State {
+	ctx := context.Background()
+	var schemaResp fwresource.SchemaResponse
+	r.Schema(ctx, fwresource.SchemaRequest{}, &schemaResp)
+	require.False(t, schemaResp.Diagnostics.HasError(), "%v", schemaResp.Diagnostics)
+
+	timeoutsType, ok := schemaResp.Schema.Blocks["timeouts"].Type().(attr.TypeWithAttributeTypes)
+	require.True(t, ok)
+	state := tfsdk.State{Schema: schemaResp.Schema}
+	diags := state.Set(ctx, &SchemaPartialModel{
+		ID:            types.StringValue(tenantID),
+		TenantID:      types.StringValue(tenantID),
+		Schema:        NewSchemaStringValue(schemaText),
+		SchemaVersion: types.StringValue(version),
+		Timeouts:      timeouts.Value{Object: types.ObjectNull(timeoutsType.AttributeTypes())},
+	})
+	require.False(t, diags.HasError(), "%v", diags)
+	return state
+}
+
+func TestSchemaPartialResourceLifecycle(t *testing.T) {
+	ctx := context.Background()
+	const tenantID = "partial"
+	const viewers = `entity organization {
+    relation viewer @user
+
+    permission read = admin or viewer
+}
+`
+
+	configure := func(t *testing.T, fake *fakePermify) fwresource.Resource {
+		r := NewSchemaPartialResource()
+		r.(fwresource.ResourceWithConfigure).Configure(ctx, fwresource.ConfigureRequest{
+			ProviderData: &providerData{client: newRetryTestClient(t, fake, 0)},
+		}, &fwresource.ConfigureResponse{})
+		return r
+	}
+	start := func(t *testing.T) *fakePermify {
+		fake := startFakePermify(t)
+		_, err := newRetryTestClient(t, fake, 0).Schema.Write(ctx, &permify_payload.SchemaWriteRequest{TenantId: tenantID, Schema: testPartialBaseSchema})
+		require.NoError(t, err)
+		return fake
+	}
+	create := func(t *testing.T, r fwresource.Resource, schemaText string) fwresource.CreateResponse {
+		state := testSchemaPartialState(t, r, tenantID, schemaText, "")
+		plan := tfsdk.Plan{Schema: state.Schema, Raw: state.Raw}
+		resp := fwresource.CreateResponse{State: tfsdk.State{Schema: state.Schema, Raw: tftypes.NewValue(state.Schema.Type().TerraformType(ctx), nil)}}
+		r.Create(ctx, fwresource.CreateRequest{Plan: plan}, &resp)
+		return resp
+	}
+	headSchema := func(t *testing.T, fake *fakePermify) string {
+		_, schema, err := readSchemaHead(ctx, newRetryTestClient(t, fake, 0), tenantID)
+		require.NoError(t, err)
+		return schema.String()
+	}
+
+	t.Run("create and delete", func(t *testing.T) {
+		fake := start(t)
+		r := configure(t, fake)
+
+		resp := create(t, r, viewers)
+		require.False(t, resp.Diagnostics.HasError(), "%v", resp.Diagnostics)
+		var model SchemaPartialModel
+		require.False(t, resp.State.Get(ctx, &model).HasError())
+		head, _ := fake.schema.head(tenantID)
+		require.Equal(t, head.version, model.SchemaVersion.ValueString())
+		require.Contains(t, headSchema(t, fake), "relation viewer @user")
+
+		deleteResp := fwresource.DeleteResponse{State: resp.State}
+		r.Delete(ctx, fwresource.DeleteRequest{State: resp.State}, &deleteResp)
+		require.False(t, deleteResp.Diagnostics.HasError(), "%v", deleteResp.Diagnostics)
+		require.Equal(t, testPartialBaseSchema, headSchema(t, fake))
+	})
+
+	t.Run("conflict", func(t *testing.T) {
+		fake := start(t)
+		r := configure(t, fake)
+
+		resp := create(t, r, "entity organization {\n    permission view = member\n}\n")
+		require.True(t, resp.Diagnostics.HasError())
+		require.Equal(t, "Conflicting Permify Schema members", resp.Diagnostics.Errors()[0].Summary())
+		require.Contains(t, resp.Diagnostics.Errors()[0].Detail(), "view of entity organization is already declared as `permission view = admin`")
+		require.Equal(t, testPartialBaseSchema, headSchema(t, fake))
+	})
+
+	t.Run("keeps writes made during the write", func(t *testing.T) {
+		var fake *fakePermify
+		var once sync.Once
+		fake = startFakePermify(t, grpc.ChainUnaryInterceptor(func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			if info.FullMethod == basev1grpc.Schema_PartialWrite_FullMethodName {
+				once.Do(func() {
+					_, err := fake.schema.Write(ctx, &permify_payload.SchemaWriteRequest{TenantId: tenantID, Schema: testPartialBaseSchema + "\nentity team {}\n"})
+					require.NoError(t, err)
+				})
+			}
+			return handler(ctx, req)
+		}))
+		_, err := newRetryTestClient(t, fake, 0).Schema.Write(ctx, &permify_payload.SchemaWriteRequest{TenantId: tenantID, Schema: testPartialBaseSchema})
+		require.NoError(t, err)
+
+		resp := create(t, configure(t, fake), viewers)
+		require.False(t, resp.Diagnostics.HasError(), "%v", resp.Diagnostics)
+		require.Contains(t, headSchema(t, fake), "entity team {}")
+		require.Contains(t, headSchema(t, fake), "relation viewer @user")
+	})
+
+	t.Run("missing schema", func(t *testing.T) {
+		resp := create(t, configure(t, startFakePermify(t)), viewers)
+		require.True(t, resp.Diagnostics.HasError())
+		require.Equal(t, "Permify Schema not found", resp.Diagnostics.Errors()[0].Summary())
+	})
+
+	t.Run("read changes made elsewhere", func(t *testing.T) {
+		fake := start(t)
+		r := configure(t, fake)
+		created := create(t, r, viewers)
+		require.False(t, created.Diagnostics.HasError(), "%v", created.Diagnostics)
+
+		client := newRetryTestClient(t, fake, 0)
+		_, err := client.Schema.PartialWrite(ctx, &permify_payload.SchemaPartialWriteRequest{
+			TenantId: tenantID,
+			Partials: map[string]*permify_payload.Partials{
+				"organization": {
+					Update: []string{"relation viewer @user @organization#member"},
+					Write:  []string{"relation auditor @user"},
+				},
+			},
+		})
+		require.NoError(t, err)
+
+		resp := fwresource.ReadResponse{State: created.State}
+		r.Read(ctx, fwresource.ReadRequest{State: created.State}, &resp)
+		require.False(t, resp.Diagnostics.HasError(), "%v", resp.Diagnostics)
+		var model SchemaPartialModel
+		require.False(t, resp.State.Get(ctx, &model).HasError())
+		require.Equal(t, `entity organization {
+    relation viewer @organization#member @user
+
+    permission read = admin or viewer
+}
+`, model.Schema.ValueString(), "members of others are left out")
+	})
+}
@@ -1,10 +1,30 @@
 package provider
 
 import (
+	"context"
 	"fmt"
+	"regexp"
+	"strings"
 	"testing"
 
+	"buf.build/gen/go/permifyco/permify/grpc/go/base/v1/basev1grpc"
+	permify_payload "buf.build/gen/go/permifyco/permify/protocolbuffers/go/base/v1"
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	fwresource "github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
 )
 
 func TestAccSchemaResource(t *testing.T) {
@@ -35,6 +55,8 @@ resource "permify_tenant" "test" {
 					resource.TestCheckResourceAttr(resourceName, "id", "test-tenant"),
 					resource.TestCheckResourceAttr(resourceName, "tenant_id", "test-tenant"),
 					resource.TestCheckResourceAttr(resourceName, "schema", testSchemaDefinition),
+					resource.TestCheckNoResourceAttr(resourceName, "previous_schema_version"),
+					resource.TestCheckResourceAttr(resourceName, "version_history.#", "1"),
 					resource.TestCheckResourceAttrWith(resourceName, "schema_version", func(value string) error {
 						schemaVersion = value
 						if value == "" {
@@ -44,10 +66,61 @@ resource "permify_tenant" "test" {
 					}),
 				),
 			},
+			// ImportState testing
+			{
+				ResourceName:      resourceName,
+				ImportState:       true,
+				ImportStateVerify: true,
+				// The schema is read back as Permify compiled it, and the
+				// imported resource has not written any version yet.
+				ImportStateVerifyIgnore: []string{"schema", "version_history"},
+				ImportStateCheck: func(states []*terraform.InstanceState) error {
+					if len(states) != 1 {
+						return fmt.Errorf("expected 1 imported resource, but got %d", len(states))
+					}
+					if imported := states[0].Attributes["schema"]; !schemasEquivalent(testSchemaDefinition, imported) {
+						return fmt.Errorf("expected the imported schema to compile like the configured one, but got %s", imported)
+					}
+					return nil
+				},
+			},
+			// Importing a pinned version
+			{
+				ResourceName: resourceName,
+				ImportState:  true,
+				ImportStateIdFunc: func(*terraform.State) (string, error) {
+					return "test-tenant/" + schemaVersion, nil
+				},
+				ImportStateCheck: func(states []*terraform.InstanceState) error {
+					if version := states[0].Attributes["schema_version"]; version != schemaVersion {
+						return fmt.Errorf("expected schema_version to be %s, but got %s", schemaVersion, version)
+					}
+					return nil
+				},
+			},
 			// Update and Read testing
 			{
 				Config: testAccSchemaResourceConfig(tenantConfig, "test-tenant", updatedSchemaDefinition),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction(resourceName, plancheck.ResourceActionUpdate),
+					},
+				},
 				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrWith(resourceName, "previous_schema_version", func(value string) error {
+						if value != schemaVersion {
+							return fmt.Errorf("expected previous_schema_version to be %s, but got %s", schemaVersion, value)
+						}
+						return nil
+					}),
+					resource.TestCheckResourceAttr(resourceName, "version_history.#", "2"),
+					resource.TestCheckResourceAttrWith(resourceName, "version_history.0", func(value string) error {
+						if value != schemaVersion {
+							return fmt.Errorf("expected version_history to start with %s, but got %s", schemaVersion, value)
+						}
+						return nil
+					}),
+					resource.TestCheckResourceAttrPair(resourceName, "version_history.1", resourceName, "schema_version"),
 					resource.TestCheckResourceAttr(resourceName, "id", "test-tenant"),
 					resource.TestCheckResourceAttr(resourceName, "tenant_id", "test-tenant"),
 					resource.TestCheckResourceAttr(resourceName, "schema", updatedSchemaDefinition),
@@ -113,6 +186,328 @@ resource "permify_tenant" "test2" {
 	})
 }
 
+func TestAccSchemaResourceCosmeticChanges(t *testing.T) {
+	resourceName := "permify_schema.test"
+
+	providerConfig := initPermify(t)
+
+	tenantConfig := providerConfig + `
+resource "permify_tenant" "test" {
+  id = "cosmetic-tenant"
+  name = "Cosmetic Tenant"
+}
+`
+	reformatted := "// Reindented, with a comment\n" + strings.ReplaceAll(testSchemaDefinition, "    ", "\t") + "\n\n"
+
+	resource.Test(t, resource.TestCase{
+		IsUnitTest: true,
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccSchemaResourceConfig(tenantConfig, "cosmetic-tenant", testSchemaDefinition),
+			},
+			// Reformatting the schema plans nothing
+			{
+				Config: testAccSchemaResourceConfig(tenantConfig, "cosmetic-tenant", reformatted),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectEmptyPlan(),
+					},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "schema", testSchemaDefinition),
+				),
+			},
+		},
+	})
+}
+
+func TestAccSchemaResourceDestroyRetain(t *testing.T) {
+	var schemaVersion string
+	providerConfig, endpoint := initPermifyWithEndpoint(t)
+	tenantConfig := testAccSchemaDestroyTenantConfig(providerConfig)
+
+	resource.Test(t, resource.TestCase{
+		IsUnitTest: true,
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccSchemaResourceDestroyConfig(tenantConfig, destroyBehaviorRetain),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("permify_schema.test", "destroy_behavior", destroyBehaviorRetain),
+					resource.TestCheckResourceAttrWith("permify_schema.test", "schema_version", func(value string) error {
+						schemaVersion = value
+						return nil
+					}),
+				),
+			},
+			// Removing the resource leaves the schema active
+			{
+				Config: tenantConfig,
+				Check: func(*terraform.State) error {
+					return testAccCheckSchemaHead(t, endpoint, func(head string) error {
+						if head != schemaVersion {
+							return fmt.Errorf("expected schema version %s to remain active, but got %s", schemaVersion, head)
+						}
+						return nil
+					})
+				},
+			},
+		},
+	})
+}
+
+func TestAccSchemaResourceDestroyClear(t *testing.T) {
+	var schemaVersion string
+	providerConfig, endpoint := initPermifyWithEndpoint(t)
+	tenantConfig := testAccSchemaDestroyTenantConfig(providerConfig)
+
+	resource.Test(t, resource.TestCase{
+		IsUnitTest: true,
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccSchemaResourceDestroyConfig(tenantConfig, destroyBehaviorClear),
+				Check: resource.TestCheckResourceAttrWith("permify_schema.test", "schema_version", func(value string) error {
+					schemaVersion = value
+					return nil
+				}),
+			},
+			// Removing the resource writes an empty schema
+			{
+				Config: tenantConfig,
+				Check: func(*terraform.State) error {
+					return testAccCheckSchemaHead(t, endpoint, func(head string) error {
+						if head == schemaVersion {
+							return fmt.Errorf("expected a new schema version, but %s is still active", head)
+						}
+						result, err := testPermifyClient(t, endpoint).Schema.Read(context.Background(), &permify_payload.SchemaReadRequest{
+							TenantId: "destroy-tenant",
+							Metadata: &permify_payload.SchemaReadRequestMetadata{SchemaVersion: head},
+						})
+						if err != nil {
+							return err
+						}
+						remote, err := FromSchemaDefinition(result.Schema)
+						if err != nil {
+							return err
+						}
+						if !schemasEquivalent(clearedSchema, remote.String()) {
+							return fmt.Errorf("expected the cleared schema, but got %s", remote)
+						}
+						return nil
+					})
+				},
+			},
+		},
+	})
+}
+
+func TestAccSchemaResourceDestroyFail(t *testing.T) {
+	providerConfig := initPermify(t)
+	tenantConfig := testAccSchemaDestroyTenantConfig(providerConfig)
+
+	resource.Test(t, resource.TestCase{
+		IsUnitTest: true,
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccSchemaResourceDestroyConfig(tenantConfig, destroyBehaviorFail),
+			},
+			// Removing the resource fails while the tenant exists
+			{
+				Config:      tenantConfig,
+				ExpectError: regexp.MustCompile("Permify Schema cannot be destroyed"),
+			},
+			// Changing the behavior lets the resource be destroyed
+			{
+				Config: testAccSchemaResourceDestroyConfig(tenantConfig, destroyBehaviorRetain),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("permify_schema.test", plancheck.ResourceActionUpdate),
+					},
+				},
+			},
+		},
+	})
+}
+
+// TestAccSchemaResourceDestroyFailWithTenant destroys a schema with
+// destroy_behavior = "fail" together with its tenant, the way its
+// documentation describes.
+func TestAccSchemaResourceDestroyFailWithTenant(t *testing.T) {
+	providerConfig, endpoint := initPermifyWithEndpoint(t)
+	tenantConfig := testAccSchemaDestroyTenantConfig(providerConfig)
+
+	resource.Test(t, resource.TestCase{
+		IsUnitTest: true,
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_7_0),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccSchemaResourceDestroyConfig(tenantConfig, destroyBehaviorFail),
+			},
+			// Destroying both at once is refused, as the schema goes first
+			{
+				Config:      providerConfig,
+				ExpectError: regexp.MustCompile("Permify Schema cannot be destroyed"),
+			},
+			// Forgetting the schema while destroying the tenant removes both
+			{
+				Config: providerConfig + `
+removed {
+  from = permify_schema.test
+
+  lifecycle {
+    destroy = false
+  }
+}
+`,
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("permify_tenant.test", plancheck.ResourceActionDestroy),
+					},
+				},
+				Check: func(*terraform.State) error {
+					tenant, err := findTenant(context.Background(), testPermifyClient(t, endpoint), "destroy-tenant")
+					if err != nil {
+						return err
+					}
+					if tenant != nil {
+						return fmt.Errorf("expected tenant destroy-tenant to be destroyed")
+					}
+					return nil
+				},
+			},
+		},
+	})
+}
+
+func TestAccSchemaResourceRollback(t *testing.T) {
+	var firstVersion string
+	providerConfig := initPermify(t)
+
+	resource.Test(t, resource.TestCase{
+		IsUnitTest: true,
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccSchemaResourceRollbackConfig(providerConfig, fmt.Sprintf("schema = %q", testSchemaDefinition)),
+				Check: resource.TestCheckResourceAttrWith("permify_schema.test", "schema_version", func(value string) error {
+					firstVersion = value
+					return nil
+				}),
+			},
+			{
+				Config: testAccSchemaResourceRollbackConfig(providerConfig, fmt.Sprintf("schema = %q", updatedSchemaDefinition)),
+			},
+			// Write the first version again
+			{
+				Config: testAccSchemaResourceRollbackConfig(providerConfig, "source_version = local.first_version"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrWith("permify_schema.test", "source_version", func(value string) error {
+						if value != firstVersion {
+							return fmt.Errorf("expected source_version %s, got %s", firstVersion, value)
+						}
+						return nil
+					}),
+					resource.TestCheckResourceAttrWith("permify_schema.test", "schema_version", func(value string) error {
+						if value == firstVersion {
+							return fmt.Errorf("expected a new schema version, got the source version %s", value)
+						}
+						return nil
+					}),
+					resource.TestCheckResourceAttr("permify_schema.test", "version_history.#", "3"),
+				),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("permify_schema.test", plancheck.ResourceActionUpdate),
+					},
+				},
+			},
+			{
+				Config:   testAccSchemaResourceRollbackConfig(providerConfig, "source_version = local.first_version"),
+				PlanOnly: true,
+			},
+		},
+	})
+}
+
+func testAccSchemaResourceRollbackConfig(providerConfig string, schemaSource string) string {
+	return providerConfig + fmt.Sprintf(`
+resource "permify_tenant" "test" {
+  id = "rollback-tenant"
+  name = "Rollback Tenant"
+}
+
+data "permify_schema_versions" "history" {
+  tenant_id = permify_tenant.test.id
+}
+
+locals {
+  versions      = data.permify_schema_versions.history.versions
+  first_version = length(local.versions) > 0 ? local.versions[length(local.versions) - 1].version : null
+}
+
+resource "permify_schema" "test" {
+  tenant_id = permify_tenant.test.id
+  %[1]s
+}
+`, schemaSource)
+}
+
+func testAccSchemaDestroyTenantConfig(providerConfig string) string {
+	return providerConfig + `
+resource "permify_tenant" "test" {
+  id = "destroy-tenant"
+  name = "Destroy Tenant"
+}
+`
+}
+
+func testAccSchemaResourceDestroyConfig(tenantConfig string, destroyBehavior string) string {
+	return tenantConfig + fmt.Sprintf(`
+resource "permify_schema" "test" {
+  tenant_id = permify_tenant.test.id
+  schema = %[1]q
+  destroy_behavior = %[2]q
+}
+`, testSchemaDefinition, destroyBehavior)
+}
+
+// testAccCheckSchemaHead checks the latest schema version of the tenant of
+// the destroy tests.
+func testAccCheckSchemaHead(t *testing.T, endpoint string, check func(head string) error) error {
+	result, err := testPermifyClient(t, endpoint).Schema.List(context.Background(), &permify_payload.SchemaListRequest{
+		TenantId: "destroy-tenant",
+		PageSize: 1,
+	})
+	if err != nil {
+		return err
+	}
+	return check(result.Head)
+}
+
 func testAccSchemaResourceConfig(providerConfig string, tenantID string, schema string) string {
 	return providerConfig + fmt.Sprintf(`
 resource "permify_schema" "test" {
@@ -167,3 +562,742 @@ entity repository {
     action delete = parent.admin
 }
 `
+
+// testSchemaState builds the state of a permify_schema resource that was
+// created with the given schema version.
+func testSchemaState(t *testing.T, r fwresource.Resource, tenantID string, schemaText string, version string, destroyBehavior string) tfsdk.State {
+	ctx := context.Background()
+	var schemaResp fwresource.SchemaResponse
+	r.Schema(ctx, fwresource.SchemaRequest{}, &schemaResp)
+	require.False(t, schemaResp.Diagnostics.HasError(), "%v", schemaResp.Diagnostics)
+
+	timeoutsType, ok := schemaResp.Schema.Blocks["timeouts"].Type().(attr.TypeWithAttributeTypes)
+	require.True(t, ok)
+	state := tfsdk.State{Schema: schemaResp.Schema}
+	diags := state.Set(ctx, &SchemaModel{
+		ID:            types.StringValue(tenantID),
+		TenantID:      types.StringValue(tenantID),
+		Schema:        NewSchemaStringValue(schemaText),
+		SchemaVersion: types.StringValue(version),
+		VersionHistory: types.ListValueMust(types.StringType, []attr.Value{
+			types.StringValue(version),
+		}),
+		DestroyBehavior: types.StringValue(destroyBehavior),
+		Timeouts:        timeouts.Value{Object: types.ObjectNull(timeoutsType.AttributeTypes())},
+	})
+	require.False(t, diags.HasError(), "%v", diags)
+	return state
+}
+
+func TestSchemaResourceReadDrift(t *testing.T) {
+	ctx := context.Background()
+	const tenantID = "drift"
+
+	read := func(t *testing.T, fake *fakePermify, version string) fwresource.ReadResponse {
+		r := NewSchemaResource()
+		r.(fwresource.ResourceWithConfigure).Configure(ctx, fwresource.ConfigureRequest{
+			ProviderData: &providerData{client: newRetryTestClient(t, fake, 0)},
+		}, &fwresource.ConfigureResponse{})
+
+		state := testSchemaState(t, r, tenantID, testSchemaDefinition, version, destroyBehaviorRetain)
+		resp := fwresource.ReadResponse{State: state}
+		r.Read(ctx, fwresource.ReadRequest{State: state}, &resp)
+		return resp
+	}
+	readModel := func(t *testing.T, resp fwresource.ReadResponse) SchemaModel {
+		var model SchemaModel
+		diags := resp.State.Get(ctx, &model)
+		require.False(t, diags.HasError(), "%v", diags)
+		return model
+	}
+
+	t.Run("unchanged", func(t *testing.T) {
+		fake := startFakePermify(t)
+		version := fake.schema.push(tenantID, testSchemaDefinition, testCompiledSchemaDefinition(t))
+
+		resp := read(t, fake, version)
+		require.False(t, resp.Diagnostics.HasError(), "%v", resp.Diagnostics)
+		model := readModel(t, resp)
+		require.Equal(t, testSchemaDefinition, model.Schema.ValueString())
+		require.Equal(t, version, model.SchemaVersion.ValueString())
+	})
+
+	t.Run("changed outside of Terraform", func(t *testing.T) {
+		fake := startFakePermify(t)
+		version := fake.schema.push(tenantID, testSchemaDefinition, &permify_payload.SchemaDefinition{})
+		head := fake.schema.push(tenantID, "", testCompiledSchemaDefinition(t))
+
+		resp := read(t, fake, version)
+		require.False(t, resp.Diagnostics.HasError(), "%v", resp.Diagnostics)
+		model := readModel(t, resp)
+		require.Equal(t, testCompiledSchema, model.Schema.ValueString())
+		require.Equal(t, head, model.SchemaVersion.ValueString())
+		require.Equal(t, version, model.PreviousSchemaVersion.ValueString())
+		require.Len(t, model.VersionHistory.Elements(), 1, "only writes by the resource are in its history")
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		fake := startFakePermify(t)
+
+		resp := read(t, fake, "v0001")
+		require.False(t, resp.Diagnostics.HasError(), "%v", resp.Diagnostics)
+		require.True(t, resp.State.Raw.IsNull())
+	})
+
+	t.Run("unavailable", func(t *testing.T) {
+		flaky := &flakyMethod{method: basev1grpc.Schema_List_FullMethodName, failures: 1, code: codes.Unavailable}
+		fake := startFakePermify(t, flaky.serverOption())
+		version := fake.schema.push(tenantID, testSchemaDefinition, &permify_payload.SchemaDefinition{})
+
+		resp := read(t, fake, version)
+		require.True(t, resp.Diagnostics.HasError())
+		require.False(t, resp.State.Raw.IsNull())
+		require.Equal(t, version, readModel(t, resp).SchemaVersion.ValueString())
+	})
+}
+
+func TestSchemaResourceSourceVersion(t *testing.T) {
+	ctx := context.Background()
+	const tenantID = "rollback"
+
+	configure := func(t *testing.T, fake *fakePermify) fwresource.Resource {
+		r := NewSchemaResource()
+		r.(fwresource.ResourceWithConfigure).Configure(ctx, fwresource.ConfigureRequest{
+			ProviderData: &providerData{client: newRetryTestClient(t, fake, 0)},
+		}, &fwresource.ConfigureResponse{})
+		return r
+	}
+	// sourceState is the state of a resource that wrote source as version,
+	// and has since been refreshed to head.
+	sourceState := func(t *testing.T, r fwresource.Resource, source string, version string, head string) tfsdk.State {
+		state := testSchemaState(t, r, tenantID, testCompiledSchema, version, destroyBehaviorRetain)
+		require.False(t, state.SetAttribute(ctx, path.Root("source_version"), source).HasError())
+		require.False(t, state.SetAttribute(ctx, path.Root("schema_version"), head).HasError())
+		return state
+	}
+
+	t.Run("create", func(t *testing.T) {
+		fake := startFakePermify(t)
+		source := fake.schema.push(tenantID, "", testCompiledSchemaDefinition(t))
+		fake.schema.push(tenantID, clearedSchema, &permify_payload.SchemaDefinition{})
+		r := configure(t, fake)
+
+		plan := sourceState(t, r, source, "", "")
+		require.False(t, plan.SetAttribute(ctx, path.Root("schema"), NewSchemaStringUnknown()).HasError())
+		resp := fwresource.CreateResponse{State: tfsdk.State{Schema: plan.Schema, Raw: tftypes.NewValue(plan.Schema.Type().TerraformType(ctx), nil)}}
+		r.Create(ctx, fwresource.CreateRequest{Plan: tfsdk.Plan{Schema: plan.Schema, Raw: plan.Raw}}, &resp)
+		require.False(t, resp.Diagnostics.HasError(), "%v", resp.Diagnostics)
+
+		var model SchemaModel
+		require.False(t, resp.State.Get(ctx, &model).HasError())
+		head, _ := fake.schema.head(tenantID)
+		require.Equal(t, testCompiledSchema, head.text)
+		require.Equal(t, testCompiledSchema, model.Schema.ValueString())
+		require.Equal(t, source, model.SourceVersion.ValueString())
+		require.Equal(t, head.version, model.SchemaVersion.ValueString())
+		require.NotEqual(t, source, head.version)
+	})
+
+	t.Run("unknown source version", func(t *testing.T) {
+		fake := startFakePermify(t)
+		fake.schema.push(tenantID, "", testCompiledSchemaDefinition(t))
+		r := configure(t, fake)
+
+		plan := sourceState(t, r, "v9999", "", "")
+		require.False(t, plan.SetAttribute(ctx, path.Root("schema"), NewSchemaStringUnknown()).HasError())
+		resp := fwresource.CreateResponse{State: tfsdk.State{Schema: plan.Schema, Raw: tftypes.NewValue(plan.Schema.Type().TerraformType(ctx), nil)}}
+		r.Create(ctx, fwresource.CreateRequest{Plan: tfsdk.Plan{Schema: plan.Schema, Raw: plan.Raw}}, &resp)
+		require.True(t, resp.Diagnostics.HasError())
+		require.Equal(t, "Permify Schema version not found", resp.Diagnostics.Errors()[0].Summary())
+	})
+
+	modifyPlan := func(t *testing.T, state tfsdk.State, source string) SchemaModel {
+		r := NewSchemaResource()
+		plan := tfsdk.Plan{Schema: state.Schema, Raw: state.Raw.Copy()}
+		require.False(t, plan.SetAttribute(ctx, path.Root("source_version"), source).HasError())
+		require.False(t, plan.SetAttribute(ctx, path.Root("schema"), NewSchemaStringUnknown()).HasError())
+		resp := fwresource.ModifyPlanResponse{Plan: plan}
+		r.(fwresource.ResourceWithModifyPlan).ModifyPlan(ctx, fwresource.ModifyPlanRequest{State: state, Plan: plan}, &resp)
+		require.False(t, resp.Diagnostics.HasError(), "%v", resp.Diagnostics)
+
+		var model SchemaModel
+		require.False(t, resp.Plan.Get(ctx, &model).HasError())
+		return model
+	}
+
+	t.Run("plan unchanged", func(t *testing.T) {
+		state := sourceState(t, NewSchemaResource(), "v0001", "v0003", "v0003")
+		model := modifyPlan(t, state, "v0001")
+		require.Equal(t, testCompiledSchema, model.Schema.ValueString())
+		require.Equal(t, "v0003", model.SchemaVersion.ValueString())
+	})
+
+	t.Run("plan another source version", func(t *testing.T) {
+		state := sourceState(t, NewSchemaResource(), "v0001", "v0003", "v0003")
+		model := modifyPlan(t, state, "v0002")
+		require.True(t, model.Schema.IsUnknown())
+		require.True(t, model.SchemaVersion.IsUnknown())
+	})
+
+	t.Run("plan after drift", func(t *testing.T) {
+		state := sourceState(t, NewSchemaResource(), "v0001", "v0003", "v0004")
+		model := modifyPlan(t, state, "v0001")
+		require.True(t, model.Schema.IsUnknown())
+		require.True(t, model.SchemaVersion.IsUnknown())
+	})
+}
+
+func TestSchemaResourceUpdateConflict(t *testing.T) {
+	ctx := context.Background()
+	const tenantID = "concurrent"
+
+	update := func(t *testing.T, fake *fakePermify, version string, force bool) fwresource.UpdateResponse {
+		r := NewSchemaResource()
+		r.(fwresource.ResourceWithConfigure).Configure(ctx, fwresource.ConfigureRequest{
+			ProviderData: &providerData{client: newRetryTestClient(t, fake, 0)},
+		}, &fwresource.ConfigureResponse{})
+
+		state := testSchemaState(t, r, tenantID, testSchemaDefinition, version, destroyBehaviorRetain)
+		plan := tfsdk.Plan{Schema: state.Schema, Raw: state.Raw.Copy()}
+		require.False(t, plan.SetAttribute(ctx, path.Root("schema"), NewSchemaStringValue(updatedSchemaDefinition)).HasError())
+		require.False(t, plan.SetAttribute(ctx, path.Root("force"), force).HasError())
+		resp := fwresource.UpdateResponse{State: state}
+		r.Update(ctx, fwresource.UpdateRequest{State: state, Plan: plan}, &resp)
+		return resp
+	}
+
+	t.Run("unchanged head", func(t *testing.T) {
+		fake := startFakePermify(t)
+		version := fake.schema.push(tenantID, testSchemaDefinition, testCompiledSchemaDefinition(t))
+
+		resp := update(t, fake, version, false)
+		require.False(t, resp.Diagnostics.HasError(), "%v", resp.Diagnostics)
+		head, _ := fake.schema.head(tenantID)
+		require.Equal(t, updatedSchemaDefinition, head.text)
+	})
+
+	t.Run("concurrent write", func(t *testing.T) {
+		fake := startFakePermify(t)
+		version := fake.schema.push(tenantID, testSchemaDefinition, testCompiledSchemaDefinition(t))
+		concurrent := fake.schema.push(tenantID, clearedSchema, &permify_payload.SchemaDefinition{})
+
+		resp := update(t, fake, version, false)
+		require.True(t, resp.Diagnostics.HasError())
+		require.Equal(t, "Conflicting Permify Schema write", resp.Diagnostics.Errors()[0].Summary())
+		require.Contains(t, resp.Diagnostics.Errors()[0].Detail(), concurrent)
+		head, _ := fake.schema.head(tenantID)
+		require.Equal(t, concurrent, head.version, "the concurrent write is kept")
+	})
+
+	t.Run("forced", func(t *testing.T) {
+		fake := startFakePermify(t)
+		version := fake.schema.push(tenantID, testSchemaDefinition, testCompiledSchemaDefinition(t))
+		fake.schema.push(tenantID, clearedSchema, &permify_payload.SchemaDefinition{})
+
+		resp := update(t, fake, version, true)
+		require.False(t, resp.Diagnostics.HasError(), "%v", resp.Diagnostics)
+		head, _ := fake.schema.head(tenantID)
+		require.Equal(t, updatedSchemaDefinition, head.text)
+	})
+}
+
+func TestSchemaResourceUpdateOrphanedData(t *testing.T) {
+	ctx := context.Background()
+	const tenantID = "orphans"
+	const withoutMaintainers = `
+entity user {}
+
+entity organization {
+    relation admin @user
+    relation member @user
+    action create_repository = admin
+    action delete = admin
+    action leave = member
+}
+
+entity repository {
+    relation parent @organization
+    relation owner @user
+    action push = owner
+    action read = owner
+    action delete = parent.admin
+}
+`
+
+	update := func(t *testing.T, fake *fakePermify, version string, allowOrphanedData bool) fwresource.UpdateResponse {
+		r := NewSchemaResource()
+		r.(fwresource.ResourceWithConfigure).Configure(ctx, fwresource.ConfigureRequest{
+			ProviderData: &providerData{client: newRetryTestClient(t, fake, 0)},
+		}, &fwresource.ConfigureResponse{})
+
+		state := testSchemaState(t, r, tenantID, testSchemaDefinition, version, destroyBehaviorRetain)
+		plan := tfsdk.Plan{Schema: state.Schema, Raw: state.Raw.Copy()}
+		require.False(t, plan.SetAttribute(ctx, path.Root("schema"), NewSchemaStringValue(withoutMaintainers)).HasError())
+		require.False(t, plan.SetAttribute(ctx, path.Root("allow_orphaned_data"), allowOrphanedData).HasError())
+		resp := fwresource.UpdateResponse{State: state}
+		r.Update(ctx, fwresource.UpdateRequest{State: state, Plan: plan}, &resp)
+		return resp
+	}
+	start := func(t *testing.T, tuples ...*permify_payload.Tuple) (*fakePermify, string) {
+		fake := startFakePermify(t)
+		fake.data.add(tenantID, tuples, nil)
+		return fake, fake.schema.push(tenantID, testSchemaDefinition, testCompiledSchemaDefinition(t))
+	}
+	maintainer := &permify_payload.Tuple{
+		Entity:   &permify_payload.Entity{Type: "repository", Id: "1"},
+		Relation: "maintainer",
+		Subject:  &permify_payload.Subject{Type: "user", Id: "1"},
+	}
+
+	t.Run("unused", func(t *testing.T) {
+		fake, version := start(t)
+
+		resp := update(t, fake, version, false)
+		require.False(t, resp.Diagnostics.HasError(), "%v", resp.Diagnostics)
+		head, _ := fake.schema.head(tenantID)
+		require.Equal(t, withoutMaintainers, head.text)
+	})
+
+	t.Run("used", func(t *testing.T) {
+		fake, version := start(t, maintainer)
+
+		resp := update(t, fake, version, false)
+		require.True(t, resp.Diagnostics.HasError())
+		require.Equal(t, "Permify Schema change would orphan data", resp.Diagnostics.Errors()[0].Summary())
+		require.Contains(t, resp.Diagnostics.Errors()[0].Detail(), "relation maintainer of entity repository, used by repository:1#maintainer@user:1")
+		head, _ := fake.schema.head(tenantID)
+		require.Equal(t, version, head.version)
+	})
+
+	t.Run("allowed", func(t *testing.T) {
+		fake, version := start(t, maintainer)
+
+		resp := update(t, fake, version, true)
+		require.False(t, resp.Diagnostics.HasError(), "%v", resp.Diagnostics)
+		head, _ := fake.schema.head(tenantID)
+		require.Equal(t, withoutMaintainers, head.text)
+	})
+}
+
+func TestSchemaResourceWaitForPropagation(t *testing.T) {
+	ctx := context.Background()
+	const tenantID = "propagation"
+
+	newResource := func(t *testing.T, fake *fakePermify) fwresource.Resource {
+		r := NewSchemaResource()
+		r.(fwresource.ResourceWithConfigure).Configure(ctx, fwresource.ConfigureRequest{
+			ProviderData: &providerData{client: newRetryTestClient(t, fake, 0)},
+		}, &fwresource.ConfigureResponse{})
+		return r
+	}
+	createWith := func(t *testing.T, r fwresource.Resource, wait types.String) fwresource.CreateResponse {
+		plan := testSchemaState(t, r, tenantID, testSchemaDefinition, "", destroyBehaviorRetain)
+		require.False(t, plan.SetAttribute(ctx, path.Root("wait_for_propagation"), wait).HasError())
+		resp := fwresource.CreateResponse{State: tfsdk.State{Schema: plan.Schema, Raw: tftypes.NewValue(plan.Schema.Type().TerraformType(ctx), nil)}}
+		r.Create(ctx, fwresource.CreateRequest{Plan: tfsdk.Plan{Schema: plan.Schema, Raw: plan.Raw}}, &resp)
+		return resp
+	}
+	create := func(t *testing.T, fake *fakePermify, wait types.String) fwresource.CreateResponse {
+		return createWith(t, newResource(t, fake), wait)
+	}
+	startWithTenant := func(t *testing.T, staleReads int) *fakePermify {
+		fake := startFakePermify(t)
+		_, err := newRetryTestClient(t, fake, 0).Tenancy.Create(ctx, &permify_payload.TenantCreateRequest{Id: tenantID, Name: tenantID})
+		require.NoError(t, err)
+		fake.schema.staleReads = staleReads
+		return fake
+	}
+
+	t.Run("propagated", func(t *testing.T) {
+		fake := startWithTenant(t, 2)
+		resp := create(t, fake, types.StringValue("5s"))
+		require.False(t, resp.Diagnostics.HasError(), "%v", resp.Diagnostics)
+		require.Zero(t, fake.schema.staleReads)
+	})
+
+	// A failed create would taint the resource, so the next apply would
+	// replace the schema it just wrote.
+	t.Run("not propagated in time on create", func(t *testing.T) {
+		fake := startWithTenant(t, 1000)
+		r := newResource(t, fake)
+		resp := createWith(t, r, types.StringValue("600ms"))
+		require.False(t, resp.Diagnostics.HasError(), "%v", resp.Diagnostics)
+		require.Len(t, resp.Diagnostics.Warnings(), 1)
+		require.Equal(t, "Permify Schema version did not propagate", resp.Diagnostics.Warnings()[0].Summary())
+		require.Contains(t, resp.Diagnostics.Warnings()[0].Detail(), "NotFound")
+
+		var model SchemaModel
+		require.False(t, resp.State.Get(ctx, &model).HasError())
+		head, _ := fake.schema.head(tenantID)
+		require.Equal(t, head.version, model.SchemaVersion.ValueString())
+
+		plan := tfsdk.Plan{Schema: resp.State.Schema, Raw: resp.State.Raw.Copy()}
+		planResp := fwresource.ModifyPlanResponse{Plan: plan}
+		r.(fwresource.ResourceWithModifyPlan).ModifyPlan(ctx, fwresource.ModifyPlanRequest{State: resp.State, Plan: plan}, &planResp)
+		require.False(t, planResp.Diagnostics.HasError(), "%v", planResp.Diagnostics)
+		var planned SchemaModel
+		require.False(t, planResp.Plan.Get(ctx, &planned).HasError())
+		require.Equal(t, head.version, planned.SchemaVersion.ValueString())
+	})
+
+	t.Run("not propagated in time on update", func(t *testing.T) {
+		fake := startWithTenant(t, 0)
+		r := newResource(t, fake)
+		created := createWith(t, r, types.StringNull())
+		require.False(t, created.Diagnostics.HasError(), "%v", created.Diagnostics)
+		fake.schema.staleReads = 1000
+
+		plan := tfsdk.Plan{Schema: created.State.Schema, Raw: created.State.Raw.Copy()}
+		require.False(t, plan.SetAttribute(ctx, path.Root("schema"), NewSchemaStringValue(updatedSchemaDefinition)).HasError())
+		require.False(t, plan.SetAttribute(ctx, path.Root("wait_for_propagation"), types.StringValue("600ms")).HasError())
+		resp := fwresource.UpdateResponse{State: created.State}
+		r.Update(ctx, fwresource.UpdateRequest{State: created.State, Plan: plan}, &resp)
+		require.True(t, resp.Diagnostics.HasError())
+		require.Equal(t, "Permify Schema version did not propagate", resp.Diagnostics.Errors()[0].Summary())
+
+		var model SchemaModel
+		require.False(t, resp.State.Get(ctx, &model).HasError())
+		head, _ := fake.schema.head(tenantID)
+		require.Equal(t, head.version, model.SchemaVersion.ValueString())
+	})
+
+	t.Run("not set", func(t *testing.T) {
+		fake := startWithTenant(t, 1000)
+		resp := create(t, fake, types.StringNull())
+		require.False(t, resp.Diagnostics.HasError(), "%v", resp.Diagnostics)
+		require.Equal(t, 1000, fake.schema.staleReads)
+	})
+
+	t.Run("invalid duration", func(t *testing.T) {
+		for _, wait := range []string{"soon", "0s"} {
+			model := SchemaModel{WaitForPropagation: types.StringValue(wait)}
+			_, err := model.propagationTimeout()
+			require.Error(t, err, wait)
+		}
+	})
+}
+
+func TestSchemaResourceModifyPlanChanges(t *testing.T) {
+	ctx := context.Background()
+
+	modifyPlan := func(t *testing.T, schemaText string) fwresource.ModifyPlanResponse {
+		r := NewSchemaResource()
+		state := testSchemaState(t, r, "changes", testSchemaDefinition, "v0001", destroyBehaviorRetain)
+		plan := tfsdk.Plan{Schema: state.Schema, Raw: state.Raw.Copy()}
+		require.False(t, plan.SetAttribute(ctx, path.Root("schema"), NewSchemaStringValue(schemaText)).HasError())
+		resp := fwresource.ModifyPlanResponse{Plan: plan}
+		r.(fwresource.ResourceWithModifyPlan).ModifyPlan(ctx, fwresource.ModifyPlanRequest{State: state, Plan: plan}, &resp)
+		require.False(t, resp.Diagnostics.HasError(), "%v", resp.Diagnostics)
+		return resp
+	}
+
+	t.Run("additions", func(t *testing.T) {
+		resp := modifyPlan(t, testSchemaDefinition+"\nentity team {}\n")
+		require.Len(t, resp.Diagnostics.Warnings(), 1)
+		warning := resp.Diagnostics.Warnings()[0]
+		require.Equal(t, "Permify Schema changes", warning.Summary())
+		require.Contains(t, warning.Detail(), "\n  + entity team\n")
+		require.Contains(t, warning.Detail(), "0 of the 1 changes are breaking")
+	})
+
+	t.Run("breaking", func(t *testing.T) {
+		withoutMaintainers := strings.NewReplacer(
+			"    relation maintainer @user @organization#member\n", "",
+			"owner and maintainer", "owner",
+		).Replace(testSchemaDefinition)
+		resp := modifyPlan(t, withoutMaintainers)
+		require.Len(t, resp.Diagnostics.Warnings(), 1)
+		warning := resp.Diagnostics.Warnings()[0]
+		require.Equal(t, "Permify Schema changes, some of them breaking", warning.Summary())
+		require.Contains(t, warning.Detail(), "\n  - relation maintainer of entity repository (breaking)\n")
+		require.Contains(t, warning.Detail(), "1 of the 2 changes are breaking")
+	})
+
+	t.Run("unchanged", func(t *testing.T) {
+		resp := modifyPlan(t, testSchemaDefinition)
+		require.Empty(t, resp.Diagnostics.Warnings())
+	})
+}
+
+func TestSchemaResourceModifyPlanLint(t *testing.T) {
+	ctx := context.Background()
+
+	modifyPlan := func(t *testing.T, rules schemaLintRules, create bool) fwresource.ModifyPlanResponse {
+		r := NewSchemaResource()
+		r.(fwresource.ResourceWithConfigure).Configure(ctx, fwresource.ConfigureRequest{
+			ProviderData: &providerData{schemaLint: rules},
+		}, &fwresource.ConfigureResponse{})
+
+		state := testSchemaState(t, r, "lint", testSchemaDefinition, "v0001", destroyBehaviorRetain)
+		plan := tfsdk.Plan{Schema: state.Schema, Raw: state.Raw.Copy()}
+		if create {
+			state.RemoveResource(ctx)
+		}
+		resp := fwresource.ModifyPlanResponse{Plan: plan}
+		r.(fwresource.ResourceWithModifyPlan).ModifyPlan(ctx, fwresource.ModifyPlanRequest{State: state, Plan: plan}, &resp)
+		return resp
+	}
+
+	t.Run("warning", func(t *testing.T) {
+		resp := modifyPlan(t, schemaLintRules{"entity_owner": lintSeverityWarning}, false)
+		require.False(t, resp.Diagnostics.HasError(), "%v", resp.Diagnostics)
+		require.Len(t, resp.Diagnostics.Warnings(), 1)
+		warning := resp.Diagnostics.Warnings()[0]
+		require.Equal(t, "Permify Schema breaks lint rule entity_owner", warning.Summary())
+		require.Contains(t, warning.Detail(), "line 4, column 8: entity organization has no owner relation")
+		require.Contains(t, warning.Detail(), "4 | entity organization {\n  |        ^")
+	})
+
+	t.Run("error on create", func(t *testing.T) {
+		resp := modifyPlan(t, schemaLintRules{"entity_owner": lintSeverityError, "no_unused_relations": lintSeverityError}, true)
+		require.Len(t, resp.Diagnostics.Errors(), 1)
+		require.Equal(t, "Permify Schema breaks lint rule entity_owner", resp.Diagnostics.Errors()[0].Summary())
+		require.Contains(t, resp.Diagnostics.Errors()[0].Detail(), "entity organization has no owner relation")
+	})
+
+	t.Run("no rules", func(t *testing.T) {
+		resp := modifyPlan(t, nil, true)
+		require.Empty(t, resp.Diagnostics)
+	})
+}
+
+func TestSchemaResourceDelete(t *testing.T) {
+	ctx := context.Background()
+	const tenantID = "destroy"
+
+	destroy := func(t *testing.T, fake *fakePermify, version string, destroyBehavior string) fwresource.DeleteResponse {
+		r := NewSchemaResource()
+		r.(fwresource.ResourceWithConfigure).Configure(ctx, fwresource.ConfigureRequest{
+			ProviderData: &providerData{client: newRetryTestClient(t, fake, 0)},
+		}, &fwresource.ConfigureResponse{})
+
+		state := testSchemaState(t, r, tenantID, testSchemaDefinition, version, destroyBehavior)
+		resp := fwresource.DeleteResponse{State: state}
+		r.Delete(ctx, fwresource.DeleteRequest{State: state}, &resp)
+		return resp
+	}
+	startWithTenant := func(t *testing.T) (*fakePermify, string) {
+		fake := startFakePermify(t)
+		_, err := newRetryTestClient(t, fake, 0).Tenancy.Create(ctx, &permify_payload.TenantCreateRequest{Id: tenantID, Name: tenantID})
+		require.NoError(t, err)
+		return fake, fake.schema.push(tenantID, testSchemaDefinition, &permify_payload.SchemaDefinition{})
+	}
+
+	t.Run("retain", func(t *testing.T) {
+		fake, version := startWithTenant(t)
+
+		resp := destroy(t, fake, version, destroyBehaviorRetain)
+		require.False(t, resp.Diagnostics.HasError(), "%v", resp.Diagnostics)
+		require.Len(t, resp.Diagnostics.Warnings(), 1)
+		require.Equal(t, "Permify Schema retained", resp.Diagnostics.Warnings()[0].Summary())
+		head, _ := fake.schema.head(tenantID)
+		require.Equal(t, version, head.version)
+	})
+
+	t.Run("clear", func(t *testing.T) {
+		fake, version := startWithTenant(t)
+
+		resp := destroy(t, fake, version, destroyBehaviorClear)
+		require.False(t, resp.Diagnostics.HasError(), "%v", resp.Diagnostics)
+		require.Empty(t, resp.Diagnostics.Warnings())
+		head, _ := fake.schema.head(tenantID)
+		require.NotEqual(t, version, head.version)
+		require.Equal(t, clearedSchema, head.text)
+	})
+
+	t.Run("fail while the tenant exists", func(t *testing.T) {
+		fake, version := startWithTenant(t)
+
+		resp := destroy(t, fake, version, destroyBehaviorFail)
+		require.True(t, resp.Diagnostics.HasError())
+		require.Equal(t, "Permify Schema cannot be destroyed", resp.Diagnostics.Errors()[0].Summary())
+	})
+
+	t.Run("fail once the tenant was deleted outside Terraform", func(t *testing.T) {
+		fake, version := startWithTenant(t)
+		_, err := newRetryTestClient(t, fake, 0).Tenancy.Delete(ctx, &permify_payload.TenantDeleteRequest{Id: tenantID})
+		require.NoError(t, err)
+
+		resp := destroy(t, fake, version, destroyBehaviorFail)
+		require.False(t, resp.Diagnostics.HasError(), "%v", resp.Diagnostics)
+	})
+}
+
+func TestSchemaResourceImport(t *testing.T) {
+	ctx := context.Background()
+	const tenantID = "imported"
+
+	fake := startFakePermify(t)
+	pinned := fake.schema.push(tenantID, "", &permify_payload.SchemaDefinition{
+		EntityDefinitions: map[string]*permify_payload.EntityDefinition{"user": {Name: "user"}},
+	})
+	head := fake.schema.push(tenantID, "", testCompiledSchemaDefinition(t))
+
+	importAndRead := func(t *testing.T, id string) fwresource.ReadResponse {
+		r := NewSchemaResource()
+		r.(fwresource.ResourceWithConfigure).Configure(ctx, fwresource.ConfigureRequest{
+			ProviderData: &providerData{client: newRetryTestClient(t, fake, 0)},
+		}, &fwresource.ConfigureResponse{})
+
+		var schemaResp fwresource.SchemaResponse
+		r.Schema(ctx, fwresource.SchemaRequest{}, &schemaResp)
+		importResp := fwresource.ImportStateResponse{State: tfsdk.State{
+			Schema: schemaResp.Schema,
+			Raw:    tftypes.NewValue(schemaResp.Schema.Type().TerraformType(ctx), nil),
+		}}
+		r.(fwresource.ResourceWithImportState).ImportState(ctx, fwresource.ImportStateRequest{ID: id}, &importResp)
+		require.False(t, importResp.Diagnostics.HasError(), "%v", importResp.Diagnostics)
+
+		resp := fwresource.ReadResponse{State: importResp.State}
+		r.Read(ctx, fwresource.ReadRequest{State: importResp.State}, &resp)
+		return resp
+	}
+
+	tests := []struct {
+		name        string
+		id          string
+		wantSchema  string
+		wantVersion string
+	}{
+		{
+			name:        "latest version",
+			id:          tenantID,
+			wantSchema:  testCompiledSchema,
+			wantVersion: head,
+		},
+		{
+			name:        "pinned version",
+			id:          tenantID + "/" + pinned,
+			wantSchema:  "entity user {}\n",
+			wantVersion: pinned,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := importAndRead(t, tt.id)
+			require.False(t, resp.Diagnostics.HasError(), "%v", resp.Diagnostics)
+
+			var model SchemaModel
+			require.False(t, resp.State.Get(ctx, &model).HasError())
+			require.Equal(t, tenantID, model.ID.ValueString())
+			require.Equal(t, tenantID, model.TenantID.ValueString())
+			require.Equal(t, tt.wantSchema, model.Schema.ValueString())
+			require.Equal(t, tt.wantVersion, model.SchemaVersion.ValueString())
+			require.True(t, model.PreviousSchemaVersion.IsNull())
+			require.False(t, model.VersionHistory.IsNull())
+			require.Empty(t, model.VersionHistory.Elements())
+			require.Equal(t, destroyBehaviorRetain, model.DestroyBehavior.ValueString())
+			require.Equal(t, types.BoolValue(false), model.Force)
+			require.Equal(t, types.BoolValue(false), model.AllowOrphanedData)
+		})
+	}
+
+	// Private state only exists behind the protocol server, so the refreshes
+	// after a pinned import go through it.
+	t.Run("pinned version is kept on refresh", func(t *testing.T) {
+		t.Setenv(envProfile, "")
+		server, err := providerserver.NewProtocol6WithError(New("test")())()
+		require.NoError(t, err)
+
+		providerConfig := testProviderConfig(t, map[string]tftypes.Value{
+			"endpoint": tftypes.NewValue(tftypes.String, fake.endpoint),
+		})
+		config, err := tfprotov6.NewDynamicValue(providerConfig.Raw.Type(), providerConfig.Raw)
+		require.NoError(t, err)
+		configureResp, err := server.ConfigureProvider(ctx, &tfprotov6.ConfigureProviderRequest{Config: &config})
+		require.NoError(t, err)
+		require.Empty(t, configureResp.Diagnostics)
+
+		importResp, err := server.ImportResourceState(ctx, &tfprotov6.ImportResourceStateRequest{
+			TypeName: "permify_schema",
+			ID:       tenantID + "/" + pinned,
+		})
+		require.NoError(t, err)
+		require.Empty(t, importResp.Diagnostics)
+		require.Len(t, importResp.ImportedResources, 1)
+
+		var schemaResp fwresource.SchemaResponse
+		NewSchemaResource().Schema(ctx, fwresource.SchemaRequest{}, &schemaResp)
+		objectType := schemaResp.Schema.Type().TerraformType(ctx)
+
+		state, private := importResp.ImportedResources[0].State, importResp.ImportedResources[0].Private
+		for range 2 {
+			readResp, err := server.ReadResource(ctx, &tfprotov6.ReadResourceRequest{
+				TypeName:     "permify_schema",
+				CurrentState: state,
+				Private:      private,
+			})
+			require.NoError(t, err)
+			require.Empty(t, readResp.Diagnostics)
+			state, private = readResp.NewState, readResp.Private
+
+			raw, err := state.Unmarshal(objectType)
+			require.NoError(t, err)
+			model := SchemaModel{}
+			require.False(t, tfsdk.State{Schema: schemaResp.Schema, Raw: raw}.Get(ctx, &model).HasError())
+			require.Equal(t, pinned, model.SchemaVersion.ValueString())
+			require.Equal(t, "entity user {}\n", model.Schema.ValueString())
+		}
+	})
+
+	t.Run("unknown version", func(t *testing.T) {
+		resp := importAndRead(t, tenantID+"/v9999")
+		require.True(t, resp.Diagnostics.HasError())
+	})
+
+	for _, id := range []string{"", "/v0001", tenantID + "/", tenantID + "/v0001/extra"} {
+		t.Run(fmt.Sprintf("invalid identifier %q", id), func(t *testing.T) {
+			var resp fwresource.ImportStateResponse
+			NewSchemaResource().(fwresource.ResourceWithImportState).ImportState(ctx, fwresource.ImportStateRequest{ID: id}, &resp)
+			require.True(t, resp.Diagnostics.HasError())
+			require.Equal(t, "Unexpected Import Identifier", resp.Diagnostics.Errors()[0].Summary())
+		})
+	}
+}
+
+func TestSchemaResourceValidateConfig(t *testing.T) {
+	ctx := context.Background()
+	r := NewSchemaResource()
+	var schemaResp fwresource.SchemaResponse
+	r.Schema(ctx, fwresource.SchemaRequest{}, &schemaResp)
+	objectType := schemaResp.Schema.Type().TerraformType(ctx).(tftypes.Object)
+
+	validate := func(schema tftypes.Value) fwresource.ValidateConfigResponse {
+		attributes := make(map[string]tftypes.Value, len(objectType.AttributeTypes))
+		for name, attributeType := range objectType.AttributeTypes {
+			attributes[name] = tftypes.NewValue(attributeType, nil)
+		}
+		attributes["tenant_id"] = tftypes.NewValue(tftypes.String, "validate")
+		attributes["schema"] = schema
+
+		var resp fwresource.ValidateConfigResponse
+		r.(fwresource.ResourceWithValidateConfig).ValidateConfig(ctx, fwresource.ValidateConfigRequest{
+			Config: tfsdk.Config{Schema: schemaResp.Schema, Raw: tftypes.NewValue(objectType, attributes)},
+		}, &resp)
+		return resp
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		resp := validate(tftypes.NewValue(tftypes.String, testSchemaDefinition))
+		require.False(t, resp.Diagnostics.HasError(), "%v", resp.Diagnostics)
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		resp := validate(tftypes.NewValue(tftypes.String, tftypes.UnknownValue))
+		require.False(t, resp.Diagnostics.HasError(), "%v", resp.Diagnostics)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		resp := validate(tftypes.NewValue(tftypes.String, "entity user {}\n\nentity doc {\n    relation owner @usr\n}\n"))
+		require.Len(t, resp.Diagnostics.Errors(), 1)
+		diagnostic := resp.Diagnostics.Errors()[0]
+		require.Equal(t, "Invalid Permify Schema", diagnostic.Summary())
+		require.Equal(t, "line 4, column 20: relation owner refers to undeclared entity usr\n\n"+
+			"4 |     relation owner @usr\n"+
+			"  |                    ^", diagnostic.Detail())
+		withPath, ok := diagnostic.(diag.DiagnosticWithPath)
+		require.True(t, ok)
+		require.Equal(t, path.Root("schema"), withPath.Path())
+	})
+}
@@ -6,6 +6,7 @@ import (
 
 	permify_payload "buf.build/gen/go/permifyco/permify/protocolbuffers/go/base/v1"
 	permify_grpc "github.com/Permify/permify-go/grpc"
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
@@ -20,7 +21,8 @@ var _ resource.ResourceWithConfigure = &tenantResource{}
 var _ resource.ResourceWithImportState = &tenantResource{}
 
 type tenantResource struct {
-	client *permify_grpc.Client
+	client        *permify_grpc.Client
+	configUnknown bool
 }
 
 func NewTenantResource() resource.Resource {
@@ -32,12 +34,13 @@ func (r *tenantResource) Configure(ctx context.Context, req resource.ConfigureRe
 		return
 	}
 
-	client, ok := req.ProviderData.(*permify_grpc.Client)
+	data, ok := req.ProviderData.(*providerData)
 	if !ok {
 		tflog.Error(ctx, "Unable to prepare client")
 		return
 	}
-	r.client = client
+	r.client = data.client
+	r.configUnknown = data.configUnknown
 }
 
 func (r *tenantResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
@@ -67,12 +70,25 @@ func (r *tenantResource) Schema(ctx context.Context, req resource.SchemaRequest,
 				},
 			},
 		},
+		Blocks: map[string]schema.Block{
+			"timeouts": timeouts.Block(ctx, timeouts.Opts{
+				Create: true,
+				Read:   true,
+				Update: true,
+				Delete: true,
+			}),
+		},
 	}
 }
 
 func (r *tenantResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
 	tflog.Debug(ctx, "Preparing to create tenant resource")
-	var data TenantModel
+	if r.client == nil {
+		resp.Diagnostics.Append(clientNotConfiguredDiagnostic())
+		return
+	}
+
+	var data TenantResourceModel
 	// Read Terraform plan data into the model
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
 
@@ -80,12 +96,20 @@ func (r *tenantResource) Create(ctx context.Context, req resource.CreateRequest,
 		return
 	}
 
+	timeout, diags := data.Timeouts.Create(ctx, defaultOperationTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
 	result, err := r.client.Tenancy.Create(ctx, &permify_payload.TenantCreateRequest{
 		Id:   data.ID.ValueString(),
 		Name: data.Name.ValueString(),
 	})
 	if err != nil {
-		resp.Diagnostics.AddError("Failed to create Permify Tenant", err.Error())
+		resp.Diagnostics.Append(operationErrorDiagnostic(ctx, "Failed to create Permify Tenant", "create", timeout, err))
 		return
 	}
 
@@ -99,16 +123,34 @@ func (r *tenantResource) Create(ctx context.Context, req resource.CreateRequest,
 
 func (r *tenantResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
 	tflog.Debug(ctx, "Preparing to read item resource")
+	if r.configUnknown {
+		tflog.Warn(ctx, "Permify provider configuration is not known yet, keeping the prior state")
+		return
+	}
+	if r.client == nil {
+		resp.Diagnostics.Append(clientNotConfiguredDiagnostic())
+		return
+	}
+
 	// Get current state
-	var state TenantModel
+	var state TenantResourceModel
 	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
+	timeout, diags := state.Timeouts.Read(ctx, defaultOperationTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
 	tenant, err := findTenant(ctx, r.client, state.ID.ValueString())
 	if err != nil {
-		resp.Diagnostics.AddError("Error reading Permify Tenant", err.Error())
+		resp.Diagnostics.Append(operationErrorDiagnostic(ctx, "Error reading Permify Tenant", "read", timeout, err))
+		return
 	}
 
 	if tenant == nil {
@@ -117,7 +159,7 @@ func (r *tenantResource) Read(ctx context.Context, req resource.ReadRequest, res
 	}
 
 	// Map resp body to model
-	state = TenantModel{
+	state.TenantModel = TenantModel{
 		ID:        types.StringValue(tenant.Id),
 		Name:      types.StringValue(tenant.Name),
 		CreatedAt: types.StringValue(tenant.CreatedAt.AsTime().Format(time.RFC3339)),
@@ -131,18 +173,47 @@ func (r *tenantResource) Read(ctx context.Context, req resource.ReadRequest, res
 	tflog.Debug(ctx, "Finished reading Permify Tenant resource", map[string]any{"success": true})
 }
 
+// Update only ever sees changes to the timeouts, as every other attribute
+// requires the tenant to be replaced, so it never calls Permify and only
+// checks that the update timeout is valid.
 func (r *tenantResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
-	resp.Diagnostics.AddError("Updating tenants is unsupported", "Cannot update a tenant, delete and recreate is required")
+	var data TenantResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	_, diags := data.Timeouts.Update(ctx, defaultOperationTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 }
 
 func (r *tenantResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	if r.client == nil {
+		resp.Diagnostics.Append(clientNotConfiguredDiagnostic())
+		return
+	}
+
 	// Retrieve values from state
-	var state TenantModel
+	var state TenantResourceModel
 	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
+	timeout, diags := state.Timeouts.Delete(ctx, defaultOperationTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
 	tflog.Debug(ctx, "Preparing to delete Permify Tenant resource", map[string]any{"id": state.ID.ValueString()})
 
 	// delete item
@@ -163,7 +234,7 @@ func (r *tenantResource) Delete(ctx context.Context, req resource.DeleteRequest,
 		PageSize: 100,
 	})
 	if err != nil {
-		resp.Diagnostics.AddError("Error listing Permify Tenants", err.Error())
+		resp.Diagnostics.Append(operationErrorDiagnostic(ctx, "Error listing Permify Tenants", "delete", timeout, err))
 		return
 	}
 	found := false
@@ -174,10 +245,7 @@ func (r *tenantResource) Delete(ctx context.Context, req resource.DeleteRequest,
 		}
 	}
 	if found && deleteErr != nil {
-		resp.Diagnostics.AddError(
-			"Error deleting Permify Tenant",
-			deleteErr.Error(),
-		)
+		resp.Diagnostics.Append(operationErrorDiagnostic(ctx, "Error deleting Permify Tenant", "delete", timeout, deleteErr))
 		return
 	}
 	tflog.Debug(ctx, "Deleted Permify Tenant resource", map[string]any{"success": true})
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
 )
 
 func TestAccTenantResource(t *testing.T) {
@@ -70,3 +71,55 @@ resource "permify_tenant" "test" {
 }
 `, id, name)
 }
+
+func TestAccTenantResourceTimeouts(t *testing.T) {
+	resourceName := "permify_tenant.test"
+
+	providerConfig := initPermify(t)
+
+	resource.Test(t, resource.TestCase{
+		IsUnitTest: true,
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccTenantResourceTimeoutsConfig(providerConfig, "1m"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "id", "timeouts"),
+					resource.TestCheckResourceAttr(resourceName, "timeouts.create", "1m"),
+					resource.TestCheckResourceAttr(resourceName, "timeouts.update", "30s"),
+				),
+			},
+			// Changing a timeout updates the tenant in place
+			{
+				Config: testAccTenantResourceTimeoutsConfig(providerConfig, "2m"),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction(resourceName, plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr(resourceName, "timeouts.create", "2m"),
+				),
+			},
+		},
+	})
+}
+
+func testAccTenantResourceTimeoutsConfig(providerConfig string, create string) string {
+	return providerConfig + fmt.Sprintf(`
+resource "permify_tenant" "test" {
+  id = "timeouts"
+  name = "Timeouts"
+
+  timeouts {
+    create = %[1]q
+    read   = "30s"
+    update = "30s"
+    delete = "30s"
+  }
+}
+`, create)
+}
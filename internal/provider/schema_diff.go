@@ -0,0 +1,169 @@
+package provider
+
+import (
+	"fmt"
+	"strings"
+)
+
+// schemaChange is a declaration that a schema change adds, removes or
+// changes.  Breaking changes can invalidate existing data, or the permission
+// checks and rule calls of clients.
+type schemaChange struct {
+	Action   string
+	Subject  string
+	Detail   string
+	Breaking bool
+}
+
+const (
+	schemaChangeAdded   = "+"
+	schemaChangeRemoved = "-"
+	schemaChangeChanged = "~"
+)
+
+func (c schemaChange) String() string {
+	line := c.Action + " " + c.Subject
+	if c.Detail != "" {
+		line += ": " + c.Detail
+	}
+	if c.Breaking {
+		line += " (breaking)"
+	}
+	return line
+}
+
+// schemaChanges lists the declarations that differ between old and new,
+// entities first and then rules, each sorted by name.  Both schemas must have
+// their declarations sorted.
+func schemaChanges(old *dslSchema, new *dslSchema) []schemaChange {
+	var changes []schemaChange
+
+	entities := map[string]bool{}
+	for _, entity := range old.Entities {
+		entities[entity.Name] = true
+	}
+	for _, entity := range new.Entities {
+		entities[entity.Name] = true
+	}
+	for _, name := range sortedKeys(entities) {
+		before, after := old.entity(name), new.entity(name)
+		switch {
+		case before == nil:
+			changes = append(changes, schemaChange{Action: schemaChangeAdded, Subject: "entity " + name})
+		case after == nil:
+			changes = append(changes, schemaChange{Action: schemaChangeRemoved, Subject: "entity " + name, Breaking: true})
+		default:
+			changes = append(changes, entityChanges(before, after)...)
+		}
+	}
+
+	oldRules, newRules := map[string]*dslRule{}, map[string]*dslRule{}
+	for _, rule := range old.Rules {
+		oldRules[rule.Name] = rule
+	}
+	for _, rule := range new.Rules {
+		newRules[rule.Name] = rule
+	}
+	rules := map[string]bool{}
+	for name := range oldRules {
+		rules[name] = true
+	}
+	for name := range newRules {
+		rules[name] = true
+	}
+	for _, name := range sortedKeys(rules) {
+		before, after := oldRules[name], newRules[name]
+		switch {
+		case before == nil:
+			changes = append(changes, schemaChange{Action: schemaChangeAdded, Subject: "rule " + name})
+		case after == nil:
+			changes = append(changes, schemaChange{Action: schemaChangeRemoved, Subject: "rule " + name, Breaking: true})
+		case before.String() != after.String():
+			changes = append(changes, schemaChange{
+				Action:   schemaChangeChanged,
+				Subject:  "rule " + name,
+				Detail:   fmt.Sprintf("`%s { %s }` to `%s { %s }`", ruleSignature(before), before.Body, ruleSignature(after), after.Body),
+				Breaking: ruleSignature(before) != ruleSignature(after),
+			})
+		}
+	}
+
+	return changes
+}
+
+func entityChanges(before *dslEntity, after *dslEntity) []schemaChange {
+	var changes []schemaChange
+	old, new := before.statements(), after.statements()
+
+	members := map[string]bool{}
+	for name := range old {
+		members[name] = true
+	}
+	for name := range new {
+		members[name] = true
+	}
+	for _, name := range sortedKeys(members) {
+		oldStatement, existed := old[name]
+		newStatement, exists := new[name]
+		switch {
+		case !existed:
+			changes = append(changes, schemaChange{
+				Action:  schemaChangeAdded,
+				Subject: fmt.Sprintf("%s of entity %s", memberKind(newStatement, name), after.Name),
+			})
+		case !exists:
+			changes = append(changes, schemaChange{
+				Action:   schemaChangeRemoved,
+				Subject:  fmt.Sprintf("%s of entity %s", memberKind(oldStatement, name), before.Name),
+				Breaking: true,
+			})
+		case oldStatement != newStatement:
+			changes = append(changes, schemaChange{
+				Action:   schemaChangeChanged,
+				Subject:  fmt.Sprintf("%s of entity %s", memberKind(newStatement, name), after.Name),
+				Detail:   fmt.Sprintf("`%s` to `%s`", oldStatement, newStatement),
+				Breaking: memberChangeBreaks(before, after, name),
+			})
+		}
+	}
+	return changes
+}
+
+// memberKind names a member by the statement declaring it, as in
+// `relation owner`.
+func memberKind(statement string, name string) string {
+	kind, _, _ := strings.Cut(statement, " ")
+	return kind + " " + name
+}
+
+// memberChangeBreaks reports whether changing the member invalidates data:
+// a relation that no longer accepts some of its subjects, an attribute of
+// another type, or a member turning into another kind of member.
+func memberChangeBreaks(before *dslEntity, after *dslEntity, name string) bool {
+	if oldRelation, newRelation := before.relation(name), after.relation(name); oldRelation != nil || newRelation != nil {
+		if oldRelation == nil || newRelation == nil {
+			return true
+		}
+		accepted := map[string]bool{}
+		for _, relationType := range newRelation.Types {
+			accepted[relationType.String()] = true
+		}
+		for _, relationType := range oldRelation.Types {
+			if !accepted[relationType.String()] {
+				return true
+			}
+		}
+		return false
+	}
+	if oldAttribute, newAttribute := before.attribute(name), after.attribute(name); oldAttribute != nil || newAttribute != nil {
+		return oldAttribute == nil || newAttribute == nil || oldAttribute.Type != newAttribute.Type
+	}
+	return false
+}
+
+// ruleSignature is the rule's name with its arguments, which permissions
+// calling it depend on.
+func ruleSignature(rule *dslRule) string {
+	signature, _, _ := strings.Cut(rule.String(), " {")
+	return signature
+}
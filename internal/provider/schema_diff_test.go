@@ -0,0 +1,102 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchemaChanges(t *testing.T) {
+	parse := func(t *testing.T, text string) *dslSchema {
+		schema, err := parseSchema(text)
+		require.NoError(t, err)
+		schema.sortDeclarations()
+		return schema
+	}
+
+	tests := []struct {
+		name string
+		old  string
+		new  string
+		want []string
+	}{
+		{
+			name: "members",
+			old:  testSchemaDefinition,
+			new:  testCompiledSchema,
+			want: []string{
+				"- permission create_repository of entity organization (breaking)",
+				"+ attribute credit of entity organization",
+				"- permission delete of entity organization (breaking)",
+				"+ permission edit of entity organization",
+				"- permission leave of entity organization (breaking)",
+				"~ relation member of entity organization: `relation member @user` to `relation member @organization#admin @user`",
+				"+ attribute tags of entity organization",
+				"+ permission view of entity organization",
+				"+ relation viewer of entity organization",
+				"~ permission delete of entity repository: `permission delete = parent.admin` to `permission delete = (owner or parent.admin) not parent.member`",
+				"- relation maintainer of entity repository (breaking)",
+				"- permission push of entity repository (breaking)",
+				"- permission read of entity repository (breaking)",
+				"+ rule check_credit",
+			},
+		},
+		{
+			name: "entities and rules",
+			old: `
+entity user {}
+entity team {}
+rule adult(age integer) { age >= 18 }
+rule open(hour integer) { hour > 8 }
+`,
+			new: `
+entity user {}
+entity document {}
+rule adult(age integer) { age >= 21 }
+rule open(hour integer, day string) { hour > 8 }
+`,
+			want: []string{
+				"+ entity document",
+				"- entity team (breaking)",
+				"~ rule adult: `rule adult(age integer) { age >= 18 }` to `rule adult(age integer) { age >= 21 }`",
+				"~ rule open: `rule open(hour integer) { hour > 8 }` to `rule open(day string, hour integer) { hour > 8 }` (breaking)",
+			},
+		},
+		{
+			name: "narrowed members",
+			old: `
+entity user {}
+entity document {
+    relation owner @user @document#owner
+    attribute size integer
+    attribute public boolean
+    permission view = owner
+}
+`,
+			new: `
+entity user {}
+entity document {
+    relation owner @user
+    attribute size double
+    relation public @user
+    permission view = owner
+}
+`,
+			want: []string{
+				"~ relation owner of entity document: `relation owner @document#owner @user` to `relation owner @user` (breaking)",
+				"~ relation public of entity document: `attribute public boolean` to `relation public @user` (breaking)",
+				"~ attribute size of entity document: `attribute size integer` to `attribute size double` (breaking)",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, change := range schemaChanges(parse(t, tt.old), parse(t, tt.new)) {
+				got = append(got, change.String())
+			}
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,169 @@
+package provider
+
+import (
+	"fmt"
+	"strings"
+)
+
+// dslSchema is the abstract syntax of a schema written in the Permify DSL.
+// It is what the provider compares and renders schemas with, as Permify
+// itself only hands back the compiled form.
+type dslSchema struct {
+	Entities []*dslEntity
+	Rules    []*dslRule
+}
+
+type dslEntity struct {
+	Name        string
+	Pos         dslPosition
+	Relations   []*dslRelation
+	Attributes  []*dslAttribute
+	Permissions []*dslPermission
+}
+
+type dslRelation struct {
+	Name  string
+	Pos   dslPosition
+	Types []dslRelationType
+}
+
+// dslRelationType is a subject type of a relation, such as `@user` or
+// `@organization#member`.
+type dslRelationType struct {
+	Entity   string
+	Relation string
+	Pos      dslPosition
+}
+
+type dslAttribute struct {
+	Name string
+	Type string
+	Pos  dslPosition
+}
+
+type dslPermission struct {
+	Name string
+	Pos  dslPosition
+	Expr dslExpr
+}
+
+type dslRule struct {
+	Name      string
+	Pos       dslPosition
+	Arguments []*dslAttribute
+	// Body is the CEL expression of the rule.
+	Body    string
+	BodyPos dslPosition
+}
+
+// dslExpr is the expression of a permission.
+type dslExpr interface {
+	render(nested bool) string
+}
+
+// dslIdent refers to a relation, a permission or an attribute of the entity,
+// or with two parts to a relation or permission of the entities related
+// through the first part.
+type dslIdent struct {
+	Parts []string
+	Pos   dslPosition
+}
+
+// dslCall calls a rule with attributes of the entity.
+type dslCall struct {
+	Rule      string
+	Arguments []string
+	Pos       dslPosition
+}
+
+// dslRewrite combines expressions with `or`, `and` or `not`.
+type dslRewrite struct {
+	Operator string
+	Children []dslExpr
+}
+
+func (s *dslSchema) String() string {
+	var blocks []string
+	for _, entity := range s.Entities {
+		blocks = append(blocks, entity.String())
+	}
+	for _, rule := range s.Rules {
+		blocks = append(blocks, rule.String())
+	}
+	return strings.Join(blocks, "\n\n") + "\n"
+}
+
+func (e *dslEntity) String() string {
+	var sections []string
+	if len(e.Relations) > 0 {
+		lines := make([]string, len(e.Relations))
+		for i, relation := range e.Relations {
+			lines[i] = "    " + relation.String()
+		}
+		sections = append(sections, strings.Join(lines, "\n"))
+	}
+	if len(e.Attributes) > 0 {
+		lines := make([]string, len(e.Attributes))
+		for i, attribute := range e.Attributes {
+			lines[i] = fmt.Sprintf("    attribute %s %s", attribute.Name, attribute.Type)
+		}
+		sections = append(sections, strings.Join(lines, "\n"))
+	}
+	if len(e.Permissions) > 0 {
+		lines := make([]string, len(e.Permissions))
+		for i, permission := range e.Permissions {
+			lines[i] = fmt.Sprintf("    permission %s = %s", permission.Name, permission.Expr.render(false))
+		}
+		sections = append(sections, strings.Join(lines, "\n"))
+	}
+
+	if len(sections) == 0 {
+		return fmt.Sprintf("entity %s {}", e.Name)
+	}
+	return fmt.Sprintf("entity %s {\n%s\n}", e.Name, strings.Join(sections, "\n\n"))
+}
+
+func (r *dslRelation) String() string {
+	types := make([]string, len(r.Types))
+	for i, relationType := range r.Types {
+		types[i] = relationType.String()
+	}
+	return fmt.Sprintf("relation %s %s", r.Name, strings.Join(types, " "))
+}
+
+func (t dslRelationType) String() string {
+	if t.Relation == "" {
+		return "@" + t.Entity
+	}
+	return "@" + t.Entity + "#" + t.Relation
+}
+
+func (r *dslRule) String() string {
+	arguments := make([]string, len(r.Arguments))
+	for i, argument := range r.Arguments {
+		arguments[i] = argument.Name + " " + argument.Type
+	}
+	return fmt.Sprintf("rule %s(%s) {\n    %s\n}", r.Name, strings.Join(arguments, ", "), r.Body)
+}
+
+func (i dslIdent) render(nested bool) string {
+	return strings.Join(i.Parts, ".")
+}
+
+func (c dslCall) render(nested bool) string {
+	return fmt.Sprintf("%s(%s)", c.Rule, strings.Join(c.Arguments, ", "))
+}
+
+// render writes nested rewrites in parentheses, so that the result does not
+// depend on the precedence of the operators.
+func (r dslRewrite) render(nested bool) string {
+	children := make([]string, len(r.Children))
+	for i, child := range r.Children {
+		children[i] = child.render(true)
+	}
+	rendered := strings.Join(children, " "+r.Operator+" ")
+	if nested {
+		return "(" + rendered + ")"
+	}
+	return rendered
+}
@@ -0,0 +1,248 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	permify_payload "buf.build/gen/go/permifyco/permify/protocolbuffers/go/base/v1"
+	"github.com/google/cel-go/cel"
+)
+
+// celTypes are the CEL types of the attribute types, as rules see them.
+var celTypes = map[string]*cel.Type{
+	"boolean":   cel.BoolType,
+	"boolean[]": cel.ListType(cel.BoolType),
+	"string":    cel.StringType,
+	"string[]":  cel.ListType(cel.StringType),
+	"integer":   cel.IntType,
+	"integer[]": cel.ListType(cel.IntType),
+	"double":    cel.DoubleType,
+	"double[]":  cel.ListType(cel.DoubleType),
+}
+
+// checkSchema parses schema text and checks that every name in it resolves
+// the way Permify's compiler requires.  Permify's compiler cannot be used
+// directly, as its generated protobuf packages register the same files as the
+// ones of the Permify client.
+func checkSchema(text string) []*dslError {
+	schema, err := parseSchema(text)
+	if err != nil {
+		var dslErr *dslError
+		if errors.As(err, &dslErr) {
+			return []*dslError{dslErr}
+		}
+		return []*dslError{{Message: err.Error()}}
+	}
+	return schema.check()
+}
+
+type dslChecker struct {
+	entities map[string]*dslEntity
+	rules    map[string]*dslRule
+	errors   []*dslError
+}
+
+func (c *dslChecker) errorf(position dslPosition, format string, args ...any) {
+	c.errors = append(c.errors, &dslError{Position: position, Message: fmt.Sprintf(format, args...)})
+}
+
+func (s *dslSchema) check() []*dslError {
+	c := &dslChecker{entities: map[string]*dslEntity{}, rules: map[string]*dslRule{}}
+
+	for _, entity := range s.Entities {
+		if _, exists := c.entities[entity.Name]; exists {
+			c.errorf(entity.Pos, "entity %s is declared more than once", entity.Name)
+			continue
+		}
+		c.entities[entity.Name] = entity
+	}
+	for _, rule := range s.Rules {
+		if _, exists := c.rules[rule.Name]; exists {
+			c.errorf(rule.Pos, "rule %s is declared more than once", rule.Name)
+			continue
+		}
+		c.rules[rule.Name] = rule
+	}
+
+	for _, entity := range s.Entities {
+		c.checkEntity(entity)
+	}
+	for _, rule := range s.Rules {
+		c.checkRule(rule)
+	}
+
+	return c.errors
+}
+
+func (c *dslChecker) checkEntity(entity *dslEntity) {
+	// Relations, attributes and permissions share one namespace.
+	declared := map[string]bool{}
+	declare := func(name string, position dslPosition) {
+		if declared[name] {
+			c.errorf(position, "%s is declared more than once in entity %s", name, entity.Name)
+		}
+		declared[name] = true
+	}
+
+	for _, relation := range entity.Relations {
+		declare(relation.Name, relation.Pos)
+		for _, relationType := range relation.Types {
+			target, ok := c.entities[relationType.Entity]
+			switch {
+			case !ok:
+				c.errorf(relationType.Pos, "relation %s refers to undeclared entity %s", relation.Name, relationType.Entity)
+			case relationType.Relation != "" && target.relation(relationType.Relation) == nil:
+				c.errorf(relationType.Pos, "entity %s has no relation %s", relationType.Entity, relationType.Relation)
+			}
+		}
+	}
+	for _, attribute := range entity.Attributes {
+		declare(attribute.Name, attribute.Pos)
+	}
+	for _, permission := range entity.Permissions {
+		declare(permission.Name, permission.Pos)
+		c.checkExpr(entity, permission.Expr)
+	}
+}
+
+func (c *dslChecker) checkExpr(entity *dslEntity, expr dslExpr) {
+	switch expr := expr.(type) {
+	case dslRewrite:
+		for _, child := range expr.Children {
+			c.checkExpr(entity, child)
+		}
+	case dslIdent:
+		if len(expr.Parts) == 1 {
+			if attribute := entity.attribute(expr.Parts[0]); attribute != nil {
+				if attribute.Type != "boolean" {
+					c.errorf(expr.Pos, "attribute %s is %s, only boolean attributes can be used as permissions", attribute.Name, attribute.Type)
+				}
+				return
+			}
+			if !entity.hasMember(expr.Parts[0]) {
+				c.errorf(expr.Pos, "entity %s has no relation, permission or attribute %s", entity.Name, expr.Parts[0])
+			}
+			return
+		}
+
+		relation := entity.relation(expr.Parts[0])
+		if relation == nil {
+			c.errorf(expr.Pos, "entity %s has no relation %s", entity.Name, expr.Parts[0])
+			return
+		}
+		for _, relationType := range relation.Types {
+			if target, ok := c.entities[relationType.Entity]; ok && target.hasMember(expr.Parts[1]) {
+				return
+			}
+		}
+		c.errorf(expr.Pos, "no entity related through %s has a relation or permission %s", expr.Parts[0], expr.Parts[1])
+	case dslCall:
+		rule, ok := c.rules[expr.Rule]
+		if !ok {
+			c.errorf(expr.Pos, "rule %s is not declared", expr.Rule)
+			return
+		}
+		if len(expr.Arguments) != len(rule.Arguments) {
+			c.errorf(expr.Pos, "rule %s takes %d arguments, but is called with %d", rule.Name, len(rule.Arguments), len(expr.Arguments))
+			return
+		}
+		for i, name := range expr.Arguments {
+			attribute := entity.attribute(name)
+			switch {
+			case attribute == nil:
+				c.errorf(expr.Pos, "entity %s has no attribute %s", entity.Name, name)
+			case attribute.Type != rule.Arguments[i].Type:
+				c.errorf(expr.Pos, "argument %s of rule %s is %s, but attribute %s is %s", rule.Arguments[i].Name, rule.Name, rule.Arguments[i].Type, name, attribute.Type)
+			}
+		}
+	}
+}
+
+func (c *dslChecker) checkRule(rule *dslRule) {
+	env, err := ruleEnv(rule)
+	if err != nil {
+		c.errorf(rule.Pos, "rule %s: %s", rule.Name, err)
+		return
+	}
+	ast, issues := env.Compile(rule.Body)
+	if issues != nil && issues.Err() != nil {
+		c.errorf(rule.BodyPos, "body of rule %s: %s", rule.Name, issues.Err())
+		return
+	}
+	// Values read from the context data are only typed once the rule runs.
+	if ast.OutputType() != cel.BoolType && ast.OutputType() != cel.DynType {
+		c.errorf(rule.BodyPos, "body of rule %s must be a boolean, but is %s", rule.Name, ast.OutputType())
+	}
+}
+
+// ruleEnv declares the variables a rule body can use: its arguments and, like
+// Permify's compiler does, the context of the request, as in
+// `context.data.ip_address`.
+func ruleEnv(rule *dslRule) (*cel.Env, error) {
+	options := []cel.EnvOption{
+		cel.Types(&permify_payload.Context{}),
+		cel.Variable("context", cel.ObjectType(string((&permify_payload.Context{}).ProtoReflect().Descriptor().FullName()))),
+	}
+	for _, argument := range rule.Arguments {
+		options = append(options, cel.Variable(argument.Name, celTypes[argument.Type]))
+	}
+	return cel.NewEnv(options...)
+}
+
+func (e *dslEntity) relation(name string) *dslRelation {
+	for _, relation := range e.Relations {
+		if relation.Name == name {
+			return relation
+		}
+	}
+	return nil
+}
+
+func (e *dslEntity) attribute(name string) *dslAttribute {
+	for _, attribute := range e.Attributes {
+		if attribute.Name == name {
+			return attribute
+		}
+	}
+	return nil
+}
+
+// hasMember reports whether the entity has a relation or permission with the
+// name.
+func (e *dslEntity) hasMember(name string) bool {
+	if e.relation(name) != nil {
+		return true
+	}
+	for _, permission := range e.Permissions {
+		if permission.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// dslSnippet quotes the line of the text at the position, with a caret under
+// the column.
+func dslSnippet(text string, position dslPosition) string {
+	lines := strings.Split(text, "\n")
+	if position.Line < 1 || position.Line > len(lines) {
+		return ""
+	}
+	line := strings.TrimRight(lines[position.Line-1], "\r")
+
+	var indent strings.Builder
+	for i, r := range []rune(line) {
+		if i >= position.Column-1 {
+			break
+		}
+		if r == '\t' {
+			indent.WriteRune('\t')
+		} else {
+			indent.WriteRune(' ')
+		}
+	}
+
+	gutter := fmt.Sprintf("%d", position.Line)
+	return fmt.Sprintf("%s | %s\n%s | %s^", gutter, line, strings.Repeat(" ", len(gutter)), indent.String())
+}
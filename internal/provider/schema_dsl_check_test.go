@@ -0,0 +1,193 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	permify_payload "buf.build/gen/go/permifyco/permify/protocolbuffers/go/base/v1"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckSchema(t *testing.T) {
+	for name, schema := range map[string]string{
+		"compiled": testCompiledSchema,
+		"written":  testWrittenSchema,
+		"initial":  testSchemaDefinition,
+		"updated":  updatedSchemaDefinition,
+		"cleared":  clearedSchema,
+		"context":  testContextSchema,
+	} {
+		t.Run(name, func(t *testing.T) {
+			require.Empty(t, checkSchema(schema))
+		})
+	}
+}
+
+// testContextSchema has a rule reading the context of permission checks.
+const testContextSchema = `entity user {}
+
+entity organization {
+    relation member @user
+    attribute ip_range string[]
+    permission view = check_ip(ip_range) and member
+}
+
+rule check_ip(ip_range string[]) {
+    context.data.ip_address in ip_range
+}`
+
+// TestAccCheckSchemaAgainstPermify checks the hand-written parser and checker
+// against Permify's own compiler, which cannot be imported: its generated
+// protobuf packages register the same files as those of the Permify client.
+// Schemas the checker accepts must compile, those it rejects must not, and
+// what Permify compiles must read back as the parsed schema.
+func TestAccCheckSchemaAgainstPermify(t *testing.T) {
+	ctx := context.Background()
+	_, endpoint := initPermifyWithEndpoint(t)
+	client := testPermifyClient(t, endpoint)
+
+	for name, text := range map[string]string{
+		"compiled": testCompiledSchema,
+		"written":  testWrittenSchema,
+		"initial":  testSchemaDefinition,
+		"updated":  updatedSchemaDefinition,
+		"context":  testContextSchema,
+	} {
+		t.Run(name, func(t *testing.T) {
+			require.Empty(t, checkSchema(text))
+			result, err := client.Schema.Write(ctx, &permify_payload.SchemaWriteRequest{TenantId: "t1", Schema: text})
+			require.NoError(t, err)
+			read, err := client.Schema.Read(ctx, &permify_payload.SchemaReadRequest{
+				TenantId: "t1",
+				Metadata: &permify_payload.SchemaReadRequestMetadata{SchemaVersion: result.SchemaVersion},
+			})
+			require.NoError(t, err)
+
+			compiled, err := FromSchemaDefinition(read.Schema)
+			require.NoError(t, err)
+			parsed, err := parseSchema(text)
+			require.NoError(t, err)
+			parsed.sortDeclarations()
+			require.Equal(t, parsed.String(), compiled.String())
+
+			fake, err := fakeCompile(parsed)
+			require.NoError(t, err)
+			fromFake, err := FromSchemaDefinition(fake)
+			require.NoError(t, err)
+			require.Equal(t, compiled.String(), fromFake.String())
+		})
+	}
+
+	for _, text := range []string{
+		"entity doc {\n    relation owner @user\n}\n",
+		"entity user {}\nentity doc {\n    relation owner @user\n    permission edit = owner or editor\n}\n",
+		"entity doc {\n    attribute age integer\n    permission view = is_adult(age)\n}\n",
+		"rule is_adult(age integer) {\n    years >= 18\n}\n",
+	} {
+		require.NotEmpty(t, checkSchema(text), text)
+		_, err := client.Schema.Write(ctx, &permify_payload.SchemaWriteRequest{TenantId: "t1", Schema: text})
+		require.Error(t, err, text)
+	}
+}
+
+func TestCheckSchemaErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		want   []string
+	}{
+		{
+			name:   "syntax",
+			schema: "entity user {\n    relation owner\n}\n",
+			want:   []string{`line 3, column 1: expected "@", found "}"`},
+		},
+		{
+			name:   "duplicate entity",
+			schema: "entity user {}\nentity user {}\n",
+			want:   []string{"line 2, column 8: entity user is declared more than once"},
+		},
+		{
+			name:   "duplicate member",
+			schema: "entity user {\n    relation friend @user\n    permission friend = friend\n}\n",
+			want:   []string{"line 3, column 16: friend is declared more than once in entity user"},
+		},
+		{
+			name:   "undeclared entity",
+			schema: "entity doc {\n    relation owner @user @team#member\n}\n",
+			want: []string{
+				"line 2, column 20: relation owner refers to undeclared entity user",
+				"line 2, column 26: relation owner refers to undeclared entity team",
+			},
+		},
+		{
+			name:   "undeclared subject relation",
+			schema: "entity user {}\nentity team {}\nentity doc {\n    relation owner @team#member\n}\n",
+			want:   []string{"line 4, column 20: entity team has no relation member"},
+		},
+		{
+			name:   "undeclared member",
+			schema: "entity user {}\nentity doc {\n    relation owner @user\n    permission edit = owner or editor\n}\n",
+			want:   []string{"line 4, column 32: entity doc has no relation, permission or attribute editor"},
+		},
+		{
+			name:   "non-boolean attribute",
+			schema: "entity doc {\n    attribute title string\n    permission view = title\n}\n",
+			want:   []string{"line 3, column 23: attribute title is string, only boolean attributes can be used as permissions"},
+		},
+		{
+			name:   "undeclared tuple set",
+			schema: "entity user {}\nentity doc {\n    permission view = parent.view\n}\n",
+			want:   []string{"line 3, column 23: entity doc has no relation parent"},
+		},
+		{
+			name:   "undeclared computed relation",
+			schema: "entity user {}\nentity folder {\n    relation owner @user\n}\nentity doc {\n    relation parent @folder\n    permission view = parent.viewer\n}\n",
+			want:   []string{"line 7, column 23: no entity related through parent has a relation or permission viewer"},
+		},
+		{
+			name:   "undeclared rule",
+			schema: "entity doc {\n    attribute age integer\n    permission view = is_adult(age)\n}\n",
+			want:   []string{"line 3, column 23: rule is_adult is not declared"},
+		},
+		{
+			name:   "wrong argument type",
+			schema: "entity doc {\n    attribute age string\n    permission view = is_adult(age)\n}\nrule is_adult(age integer) {\n    age >= 18\n}\n",
+			want:   []string{"line 3, column 23: argument age of rule is_adult is integer, but attribute age is string"},
+		},
+		{
+			name:   "rule not boolean",
+			schema: "rule is_adult(age integer) {\n    age + 18\n}\n",
+			want:   []string{"line 1, column 28: body of rule is_adult must be a boolean, but is int"},
+		},
+		{
+			name:   "context field that does not exist",
+			schema: "rule from_office(ip string) {\n    context.headers.ip == ip\n}\n",
+			want:   []string{"line 1, column 29: body of rule from_office: ERROR: <input>:1:8: undefined field 'headers'"},
+		},
+		{
+			name:   "rule with undeclared variable",
+			schema: "rule is_adult(age integer) {\n    years >= 18\n}\n",
+			want:   []string{"line 1, column 28: body of rule is_adult: ERROR: <input>:1:1: undeclared reference to 'years'"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := checkSchema(tt.schema)
+			got := make([]string, len(errs))
+			for i, err := range errs {
+				got[i] = err.Error()
+			}
+			require.Len(t, got, len(tt.want), "%v", got)
+			for i := range tt.want {
+				require.Contains(t, got[i], tt.want[i])
+			}
+		})
+	}
+}
+
+func TestDSLSnippet(t *testing.T) {
+	text := "entity doc {\n\trelation owner @usr\n}\n"
+	require.Equal(t, "2 | \trelation owner @usr\n  | \t               ^", dslSnippet(text, dslPosition{Line: 2, Column: 17}))
+	require.Empty(t, dslSnippet(text, dslPosition{}))
+}
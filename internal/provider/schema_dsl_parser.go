@@ -0,0 +1,536 @@
+package provider
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/google/cel-go/cel"
+)
+
+// dslPosition is a place in schema text, counted from 1 like Permify does.
+type dslPosition struct {
+	Line   int
+	Column int
+}
+
+func (p dslPosition) String() string {
+	return fmt.Sprintf("line %d, column %d", p.Line, p.Column)
+}
+
+// dslError is a problem with schema text, and where it is.
+type dslError struct {
+	Position dslPosition
+	Message  string
+}
+
+func (e *dslError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Position, e.Message)
+}
+
+type dslTokenKind int
+
+const (
+	dslTokenEOF dslTokenKind = iota
+	dslTokenIdent
+	dslTokenSymbol
+	dslTokenString
+)
+
+type dslToken struct {
+	kind     dslTokenKind
+	text     string
+	position dslPosition
+	// offset is where the token starts in the text, used to cut out the
+	// bodies of rules.
+	offset int
+}
+
+func (t dslToken) describe() string {
+	switch t.kind {
+	case dslTokenEOF:
+		return "end of schema"
+	default:
+		return fmt.Sprintf("%q", t.text)
+	}
+}
+
+// lexSchema splits schema text into identifiers and symbols, dropping
+// whitespace and `//` and `/* */` comments.
+func lexSchema(text string) ([]dslToken, error) {
+	var tokens []dslToken
+	runes := []rune(text)
+	line, column := 1, 1
+	offsets := make([]int, len(runes)+1)
+	for i, offset := 0, 0; i < len(runes); i++ {
+		offsets[i] = offset
+		offset += len(string(runes[i]))
+		offsets[i+1] = offset
+	}
+
+	advance := func(i int, n int) int {
+		for ; n > 0 && i < len(runes); n-- {
+			if runes[i] == '\n' {
+				line++
+				column = 1
+			} else {
+				column++
+			}
+			i++
+		}
+		return i
+	}
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		position := dslPosition{Line: line, Column: column}
+		switch {
+		case unicode.IsSpace(r):
+			i = advance(i, 1)
+		case r == '/' && i+1 < len(runes) && runes[i+1] == '/':
+			for i < len(runes) && runes[i] != '\n' {
+				i = advance(i, 1)
+			}
+		case r == '/' && i+1 < len(runes) && runes[i+1] == '*':
+			i = advance(i, 2)
+			for i < len(runes) && !(runes[i] == '*' && i+1 < len(runes) && runes[i+1] == '/') {
+				i = advance(i, 1)
+			}
+			if i == len(runes) {
+				return nil, &dslError{Position: position, Message: "comment is not closed"}
+			}
+			i = advance(i, 2)
+		case r == '"' || r == '\'':
+			// Strings only appear in the CEL of rules, but may hold anything
+			// that would otherwise read as a comment or a brace.
+			start := i
+			i = advance(i, 1)
+			for i < len(runes) && runes[i] != r && runes[i] != '\n' {
+				if runes[i] == '\\' {
+					i = advance(i, 1)
+				}
+				i = advance(i, 1)
+			}
+			if i >= len(runes) || runes[i] != r {
+				return nil, &dslError{Position: position, Message: "string is not closed"}
+			}
+			i = advance(i, 1)
+			tokens = append(tokens, dslToken{kind: dslTokenString, text: string(runes[start:i]), position: position, offset: offsets[start]})
+		case r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r):
+			start := i
+			for i < len(runes) && (runes[i] == '_' || unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i])) {
+				i = advance(i, 1)
+			}
+			tokens = append(tokens, dslToken{kind: dslTokenIdent, text: string(runes[start:i]), position: position, offset: offsets[start]})
+		default:
+			tokens = append(tokens, dslToken{kind: dslTokenSymbol, text: string(r), position: position, offset: offsets[i]})
+			i = advance(i, 1)
+		}
+	}
+
+	return append(tokens, dslToken{kind: dslTokenEOF, position: dslPosition{Line: line, Column: column}, offset: len(text)}), nil
+}
+
+// parseSchema parses schema text written in the Permify DSL.  `action` is
+// read as `permission`, and the expressions of rules are normalized by CEL,
+// so that the result only keeps what Permify compiles.
+func parseSchema(text string) (*dslSchema, error) {
+	tokens, err := lexSchema(text)
+	if err != nil {
+		return nil, err
+	}
+	p := &dslParser{text: text, tokens: tokens}
+	return p.parseSchema()
+}
+
+type dslParser struct {
+	text   string
+	tokens []dslToken
+	next   int
+}
+
+func (p *dslParser) peek() dslToken {
+	return p.tokens[p.next]
+}
+
+func (p *dslParser) take() dslToken {
+	token := p.tokens[p.next]
+	if token.kind != dslTokenEOF {
+		p.next++
+	}
+	return token
+}
+
+func (p *dslParser) errorf(token dslToken, format string, args ...any) error {
+	return &dslError{Position: token.position, Message: fmt.Sprintf(format, args...)}
+}
+
+func (p *dslParser) expectSymbol(symbol string) (dslToken, error) {
+	token := p.take()
+	if token.kind != dslTokenSymbol || token.text != symbol {
+		return token, p.errorf(token, "expected %q, found %s", symbol, token.describe())
+	}
+	return token, nil
+}
+
+func (p *dslParser) expectIdent(what string) (string, error) {
+	token := p.take()
+	if token.kind != dslTokenIdent {
+		return "", p.errorf(token, "expected %s, found %s", what, token.describe())
+	}
+	return token.text, nil
+}
+
+func (p *dslParser) atSymbol(symbol string) bool {
+	token := p.peek()
+	return token.kind == dslTokenSymbol && token.text == symbol
+}
+
+func (p *dslParser) parseSchema() (*dslSchema, error) {
+	schema := &dslSchema{}
+	for {
+		token := p.take()
+		switch {
+		case token.kind == dslTokenEOF:
+			return schema, nil
+		case token.kind == dslTokenIdent && token.text == "entity":
+			entity, err := p.parseEntity()
+			if err != nil {
+				return nil, err
+			}
+			schema.Entities = append(schema.Entities, entity)
+		case token.kind == dslTokenIdent && token.text == "rule":
+			rule, err := p.parseRule()
+			if err != nil {
+				return nil, err
+			}
+			schema.Rules = append(schema.Rules, rule)
+		default:
+			return nil, p.errorf(token, "expected \"entity\" or \"rule\", found %s", token.describe())
+		}
+	}
+}
+
+func (p *dslParser) parseEntity() (*dslEntity, error) {
+	position := p.peek().position
+	name, err := p.expectIdent("entity name")
+	if err != nil {
+		return nil, err
+	}
+	entity := &dslEntity{Name: name, Pos: position}
+	if _, err := p.expectSymbol("{"); err != nil {
+		return nil, err
+	}
+
+	for {
+		token := p.take()
+		if token.kind == dslTokenSymbol && token.text == "}" {
+			return entity, nil
+		}
+		if token.kind != dslTokenIdent {
+			return nil, p.errorf(token, "expected \"relation\", \"attribute\", \"permission\" or \"}\", found %s", token.describe())
+		}
+
+		switch token.text {
+		case "relation":
+			relation, err := p.parseRelation()
+			if err != nil {
+				return nil, err
+			}
+			entity.Relations = append(entity.Relations, relation)
+		case "attribute":
+			attribute, err := p.parseAttribute("attribute name")
+			if err != nil {
+				return nil, err
+			}
+			entity.Attributes = append(entity.Attributes, attribute)
+		case "permission", "action":
+			permission, err := p.parsePermission()
+			if err != nil {
+				return nil, err
+			}
+			entity.Permissions = append(entity.Permissions, permission)
+		default:
+			return nil, p.errorf(token, "expected \"relation\", \"attribute\", \"permission\" or \"}\", found %s", token.describe())
+		}
+	}
+}
+
+func (p *dslParser) parseRelation() (*dslRelation, error) {
+	position := p.peek().position
+	name, err := p.expectIdent("relation name")
+	if err != nil {
+		return nil, err
+	}
+	relation := &dslRelation{Name: name, Pos: position}
+
+	for p.atSymbol("@") || len(relation.Types) == 0 {
+		at, err := p.expectSymbol("@")
+		if err != nil {
+			return nil, err
+		}
+		entity, err := p.expectIdent("entity name")
+		if err != nil {
+			return nil, err
+		}
+		relationType := dslRelationType{Entity: entity, Pos: at.position}
+		if p.atSymbol("#") {
+			p.take()
+			if relationType.Relation, err = p.expectIdent("relation name"); err != nil {
+				return nil, err
+			}
+		}
+		relation.Types = append(relation.Types, relationType)
+	}
+
+	return relation, nil
+}
+
+// parseAttribute parses a name followed by an attribute type, as in entity
+// attributes and rule arguments.
+func (p *dslParser) parseAttribute(what string) (*dslAttribute, error) {
+	position := p.peek().position
+	name, err := p.expectIdent(what)
+	if err != nil {
+		return nil, err
+	}
+	typeToken := p.peek()
+	attributeType, err := p.expectIdent("attribute type")
+	if err != nil {
+		return nil, err
+	}
+	if p.atSymbol("[") {
+		p.take()
+		if _, err := p.expectSymbol("]"); err != nil {
+			return nil, err
+		}
+		attributeType += "[]"
+	}
+	if !isAttributeType(attributeType) {
+		return nil, p.errorf(typeToken, "unknown attribute type %q", attributeType)
+	}
+	return &dslAttribute{Name: name, Type: attributeType, Pos: position}, nil
+}
+
+func isAttributeType(name string) bool {
+	for _, attributeType := range attributeTypes {
+		if attributeType == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *dslParser) parsePermission() (*dslPermission, error) {
+	position := p.peek().position
+	name, err := p.expectIdent("permission name")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := p.expectSymbol("="); err != nil {
+		return nil, err
+	}
+	expr, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+	return &dslPermission{Name: name, Expr: expr, Pos: position}, nil
+}
+
+// parseExpr parses operators left to right: Permify gives `or`, `and` and
+// `not` the same precedence.
+func (p *dslParser) parseExpr() (dslExpr, error) {
+	expr, err := p.parseTerm()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		token := p.peek()
+		if token.kind != dslTokenIdent || (token.text != "or" && token.text != "and" && token.text != "not") {
+			return expr, nil
+		}
+		p.take()
+		right, err := p.parseTerm()
+		if err != nil {
+			return nil, err
+		}
+		expr = flattenRewrite(dslRewrite{Operator: token.text, Children: []dslExpr{expr, right}})
+	}
+}
+
+func (p *dslParser) parseTerm() (dslExpr, error) {
+	if p.atSymbol("(") {
+		p.take()
+		expr, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.expectSymbol(")"); err != nil {
+			return nil, err
+		}
+		return expr, nil
+	}
+
+	position := p.peek().position
+	name, err := p.expectIdent("relation, permission, attribute or rule")
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case p.atSymbol("."):
+		p.take()
+		member, err := p.expectIdent("relation or permission")
+		if err != nil {
+			return nil, err
+		}
+		return dslIdent{Parts: []string{name, member}, Pos: position}, nil
+	case p.atSymbol("("):
+		p.take()
+		call := dslCall{Rule: name, Pos: position}
+		for !p.atSymbol(")") {
+			if len(call.Arguments) > 0 {
+				if _, err := p.expectSymbol(","); err != nil {
+					return nil, err
+				}
+			}
+			argument, err := p.expectIdent("attribute")
+			if err != nil {
+				return nil, err
+			}
+			call.Arguments = append(call.Arguments, argument)
+		}
+		p.take()
+		return call, nil
+	}
+	return dslIdent{Parts: []string{name}, Pos: position}, nil
+}
+
+func (p *dslParser) parseRule() (*dslRule, error) {
+	position := p.peek().position
+	name, err := p.expectIdent("rule name")
+	if err != nil {
+		return nil, err
+	}
+	rule := &dslRule{Name: name, Pos: position}
+
+	if _, err := p.expectSymbol("("); err != nil {
+		return nil, err
+	}
+	for !p.atSymbol(")") {
+		if len(rule.Arguments) > 0 {
+			if _, err := p.expectSymbol(","); err != nil {
+				return nil, err
+			}
+		}
+		argument, err := p.parseAttribute("argument name")
+		if err != nil {
+			return nil, err
+		}
+		rule.Arguments = append(rule.Arguments, argument)
+	}
+	p.take()
+
+	// The body is CEL rather than DSL, so it is cut out of the text up to the
+	// matching brace.
+	open, err := p.expectSymbol("{")
+	if err != nil {
+		return nil, err
+	}
+	depth := 1
+	for depth > 0 {
+		token := p.take()
+		switch {
+		case token.kind == dslTokenEOF:
+			return nil, p.errorf(open, "body of rule %s is not closed", name)
+		case token.kind == dslTokenSymbol && token.text == "{":
+			depth++
+		case token.kind == dslTokenSymbol && token.text == "}":
+			depth--
+			if depth == 0 {
+				body, err := normalizeRuleBody(p.text[open.offset+1 : token.offset])
+				if err != nil {
+					return nil, p.errorf(open, "body of rule %s: %s", name, err)
+				}
+				rule.Body = body
+				rule.BodyPos = open.position
+			}
+		}
+	}
+
+	return rule, nil
+}
+
+// normalizeRuleBody parses a CEL expression and writes it back out, which is
+// also how rules read from Permify are rendered.
+func normalizeRuleBody(body string) (string, error) {
+	env, err := cel.NewEnv()
+	if err != nil {
+		return "", err
+	}
+	ast, issues := env.Parse(strings.TrimSpace(body))
+	if issues != nil && issues.Err() != nil {
+		return "", issues.Err()
+	}
+	return cel.AstToString(ast)
+}
+
+// sortDeclarations puts the schema in the form FromSchemaDefinition returns,
+// where declarations are sorted by name, and orders the operands of `or` and
+// `and`, which Permify evaluates in any order.
+func (s *dslSchema) sortDeclarations() {
+	sort.Slice(s.Entities, func(i, j int) bool { return s.Entities[i].Name < s.Entities[j].Name })
+	sort.Slice(s.Rules, func(i, j int) bool { return s.Rules[i].Name < s.Rules[j].Name })
+
+	for _, entity := range s.Entities {
+		sort.Slice(entity.Relations, func(i, j int) bool { return entity.Relations[i].Name < entity.Relations[j].Name })
+		sort.Slice(entity.Attributes, func(i, j int) bool { return entity.Attributes[i].Name < entity.Attributes[j].Name })
+		sort.Slice(entity.Permissions, func(i, j int) bool { return entity.Permissions[i].Name < entity.Permissions[j].Name })
+		for _, relation := range entity.Relations {
+			sort.Slice(relation.Types, func(i, j int) bool { return relation.Types[i].String() < relation.Types[j].String() })
+		}
+		for _, permission := range entity.Permissions {
+			permission.Expr = sortOperands(permission.Expr)
+		}
+	}
+	for _, rule := range s.Rules {
+		sort.Slice(rule.Arguments, func(i, j int) bool { return rule.Arguments[i].Name < rule.Arguments[j].Name })
+	}
+}
+
+func sortOperands(expr dslExpr) dslExpr {
+	rewrite, ok := expr.(dslRewrite)
+	if !ok {
+		return expr
+	}
+	children := make([]dslExpr, len(rewrite.Children))
+	for i, child := range rewrite.Children {
+		children[i] = sortOperands(child)
+	}
+	rewrite = flattenRewrite(dslRewrite{Operator: rewrite.Operator, Children: children})
+	if rewrite.Operator != "not" {
+		sort.SliceStable(rewrite.Children, func(i, j int) bool {
+			return rewrite.Children[i].render(true) < rewrite.Children[j].render(true)
+		})
+	}
+	return rewrite
+}
+
+// schemasEquivalent reports whether two schema texts compile to the same
+// schema.  Text that does not parse is only equivalent to identical text.
+func schemasEquivalent(a string, b string) bool {
+	if a == b {
+		return true
+	}
+	schemaA, err := parseSchema(a)
+	if err != nil {
+		return false
+	}
+	schemaB, err := parseSchema(b)
+	if err != nil {
+		return false
+	}
+	schemaA.sortDeclarations()
+	schemaB.sortDeclarations()
+	return schemaA.String() == schemaB.String()
+}
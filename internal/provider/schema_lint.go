@@ -0,0 +1,189 @@
+package provider
+
+import (
+	"fmt"
+	"regexp"
+)
+
+const (
+	lintSeverityWarning = "warning"
+	lintSeverityError   = "error"
+)
+
+// lintRuleNames are the rules `schema_lint` can enable, in the order they are
+// reported.
+var lintRuleNames = []string{
+	"snake_case_relations",
+	"entity_owner",
+	"no_unused_relations",
+	"no_undefined_relations",
+}
+
+var lintRules = map[string]func(schema *dslSchema) []*dslError{
+	"snake_case_relations":   lintSnakeCaseRelations,
+	"entity_owner":           lintEntityOwner,
+	"no_unused_relations":    lintUnusedRelations,
+	"no_undefined_relations": lintUndefinedRelations,
+}
+
+// schemaLintRules maps the enabled rules to their severity.
+type schemaLintRules map[string]string
+
+// schemaLintFinding is a declaration that breaks an enabled rule.
+type schemaLintFinding struct {
+	Rule     string
+	Severity string
+	Err      *dslError
+}
+
+// lint runs the enabled rules against the schema, which is only read, never
+// sent anywhere.
+func (rules schemaLintRules) lint(schema *dslSchema) []schemaLintFinding {
+	var findings []schemaLintFinding
+	for _, name := range lintRuleNames {
+		severity, enabled := rules[name]
+		if !enabled {
+			continue
+		}
+		for _, err := range lintRules[name](schema) {
+			findings = append(findings, schemaLintFinding{Rule: name, Severity: severity, Err: err})
+		}
+	}
+	return findings
+}
+
+var snakeCasePattern = regexp.MustCompile(`^[a-z][a-z0-9]*(_[a-z0-9]+)*$`)
+
+func lintSnakeCaseRelations(schema *dslSchema) []*dslError {
+	var errs []*dslError
+	for _, entity := range schema.Entities {
+		for _, relation := range entity.Relations {
+			if !snakeCasePattern.MatchString(relation.Name) {
+				errs = append(errs, &dslError{
+					Position: relation.Pos,
+					Message:  fmt.Sprintf("relation %s of entity %s is not in snake_case", relation.Name, entity.Name),
+				})
+			}
+		}
+	}
+	return errs
+}
+
+// lintEntityOwner requires an `owner` relation on every entity that has
+// relations.  Entities without any, such as `user`, are only ever subjects.
+func lintEntityOwner(schema *dslSchema) []*dslError {
+	var errs []*dslError
+	for _, entity := range schema.Entities {
+		if len(entity.Relations) > 0 && entity.relation("owner") == nil {
+			errs = append(errs, &dslError{
+				Position: entity.Pos,
+				Message:  fmt.Sprintf("entity %s has no owner relation", entity.Name),
+			})
+		}
+	}
+	return errs
+}
+
+// lintUnusedRelations reports relations that no permission refers to, either
+// directly or through another entity's relation, and that no relation accepts
+// as a subject set.
+func lintUnusedRelations(schema *dslSchema) []*dslError {
+	used := map[string]map[string]bool{}
+	use := func(entity string, relation string) {
+		if used[entity] == nil {
+			used[entity] = map[string]bool{}
+		}
+		used[entity][relation] = true
+	}
+
+	for _, entity := range schema.Entities {
+		for _, relation := range entity.Relations {
+			for _, relationType := range relation.Types {
+				if relationType.Relation != "" {
+					use(relationType.Entity, relationType.Relation)
+				}
+			}
+		}
+		for _, permission := range entity.Permissions {
+			walkIdents(permission.Expr, func(ident dslIdent) {
+				use(entity.Name, ident.Parts[0])
+				if len(ident.Parts) < 2 {
+					return
+				}
+				if relation := entity.relation(ident.Parts[0]); relation != nil {
+					for _, relationType := range relation.Types {
+						use(relationType.Entity, ident.Parts[1])
+					}
+				}
+			})
+		}
+	}
+
+	var errs []*dslError
+	for _, entity := range schema.Entities {
+		for _, relation := range entity.Relations {
+			if !used[entity.Name][relation.Name] {
+				errs = append(errs, &dslError{
+					Position: relation.Pos,
+					Message:  fmt.Sprintf("relation %s of entity %s is not used by any permission", relation.Name, entity.Name),
+				})
+			}
+		}
+	}
+	return errs
+}
+
+// lintUndefinedRelations reports permissions that refer to relations their
+// entity does not declare, or that none of the entities related through a
+// relation declare.
+func lintUndefinedRelations(schema *dslSchema) []*dslError {
+	var errs []*dslError
+	for _, entity := range schema.Entities {
+		for _, permission := range entity.Permissions {
+			walkIdents(permission.Expr, func(ident dslIdent) {
+				name := ident.Parts[0]
+				if len(ident.Parts) == 1 {
+					if !entity.hasMember(name) && entity.attribute(name) == nil {
+						errs = append(errs, &dslError{
+							Position: ident.Pos,
+							Message:  fmt.Sprintf("permission %s of entity %s refers to undefined relation %s", permission.Name, entity.Name, name),
+						})
+					}
+					return
+				}
+
+				relation := entity.relation(name)
+				if relation == nil {
+					errs = append(errs, &dslError{
+						Position: ident.Pos,
+						Message:  fmt.Sprintf("permission %s of entity %s refers to undefined relation %s", permission.Name, entity.Name, name),
+					})
+					return
+				}
+				for _, relationType := range relation.Types {
+					if target := schema.entity(relationType.Entity); target != nil && target.hasMember(ident.Parts[1]) {
+						return
+					}
+				}
+				errs = append(errs, &dslError{
+					Position: ident.Pos,
+					Message:  fmt.Sprintf("permission %s of entity %s refers to %s, which no entity related through %s declares", permission.Name, entity.Name, ident.Parts[1], name),
+				})
+			})
+		}
+	}
+	return errs
+}
+
+// walkIdents calls visit with every relation, permission or attribute the
+// expression refers to.
+func walkIdents(expr dslExpr, visit func(ident dslIdent)) {
+	switch expr := expr.(type) {
+	case dslRewrite:
+		for _, child := range expr.Children {
+			walkIdents(child, visit)
+		}
+	case dslIdent:
+		visit(expr)
+	}
+}
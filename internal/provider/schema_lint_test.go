@@ -0,0 +1,84 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchemaLint(t *testing.T) {
+	const text = `
+entity user {}
+
+entity organization {
+    relation owner @user
+    relation admin @user
+    relation teamMember @user
+    relation billing @user
+    action manage = owner or admin
+    action join = teamMember
+    action pay = billing_admin
+}
+
+entity repository {
+    relation parent @organization
+    relation maintainer @user @organization#teamMember
+    action delete = parent.admin or maintainer
+    action push = parent.owner or parent.auditor
+}
+`
+	schema, err := parseSchema(text)
+	require.NoError(t, err)
+
+	tests := []struct {
+		rule string
+		want []string
+	}{
+		{
+			rule: "snake_case_relations",
+			want: []string{"line 7, column 14: relation teamMember of entity organization is not in snake_case"},
+		},
+		{
+			rule: "entity_owner",
+			want: []string{"line 14, column 8: entity repository has no owner relation"},
+		},
+		{
+			rule: "no_unused_relations",
+			want: []string{"line 8, column 14: relation billing of entity organization is not used by any permission"},
+		},
+		{
+			rule: "no_undefined_relations",
+			want: []string{
+				"line 11, column 18: permission pay of entity organization refers to undefined relation billing_admin",
+				"line 18, column 35: permission push of entity repository refers to auditor, which no entity related through parent declares",
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.rule, func(t *testing.T) {
+			findings := schemaLintRules{test.rule: lintSeverityWarning}.lint(schema)
+			var got []string
+			for _, finding := range findings {
+				require.Equal(t, test.rule, finding.Rule)
+				require.Equal(t, lintSeverityWarning, finding.Severity)
+				got = append(got, finding.Err.Error())
+			}
+			require.Equal(t, test.want, got)
+		})
+	}
+
+	t.Run("all rules in order", func(t *testing.T) {
+		findings := schemaLintRules{
+			"no_undefined_relations": lintSeverityError,
+			"snake_case_relations":   lintSeverityWarning,
+		}.lint(schema)
+		require.Len(t, findings, 3)
+		require.Equal(t, "snake_case_relations", findings[0].Rule)
+		require.Equal(t, "no_undefined_relations", findings[1].Rule)
+		require.Equal(t, lintSeverityError, findings[1].Severity)
+	})
+
+	t.Run("none enabled", func(t *testing.T) {
+		require.Empty(t, schemaLintRules{}.lint(schema))
+	})
+}
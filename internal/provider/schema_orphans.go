@@ -0,0 +1,140 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	permify_payload "buf.build/gen/go/permifyco/permify/protocolbuffers/go/base/v1"
+	permify_grpc "github.com/Permify/permify-go/grpc"
+)
+
+// orphanSampleSize is how many of the tuples or attributes left behind by a
+// removal are quoted, out of however many there are.
+const orphanSampleSize = 3
+
+// schemaRemoval is an entity, or a relation or attribute of an entity, that a
+// schema change removes.  Exactly one of Relation and Attribute is set for
+// members, and neither for whole entities.
+type schemaRemoval struct {
+	Entity    string
+	Relation  string
+	Attribute string
+}
+
+func (r schemaRemoval) String() string {
+	switch {
+	case r.Relation != "":
+		return fmt.Sprintf("relation %s of entity %s", r.Relation, r.Entity)
+	case r.Attribute != "":
+		return fmt.Sprintf("attribute %s of entity %s", r.Attribute, r.Entity)
+	}
+	return fmt.Sprintf("entity %s", r.Entity)
+}
+
+// schemaRemovals lists what the change from old to new removes.  The members
+// of a removed entity are not listed separately.
+func schemaRemovals(old *dslSchema, new *dslSchema) []schemaRemoval {
+	var removals []schemaRemoval
+	for _, entity := range old.Entities {
+		kept := new.entity(entity.Name)
+		if kept == nil {
+			removals = append(removals, schemaRemoval{Entity: entity.Name})
+			continue
+		}
+		for _, relation := range entity.Relations {
+			if kept.relation(relation.Name) == nil {
+				removals = append(removals, schemaRemoval{Entity: entity.Name, Relation: relation.Name})
+			}
+		}
+		for _, attribute := range entity.Attributes {
+			if kept.attribute(attribute.Name) == nil {
+				removals = append(removals, schemaRemoval{Entity: entity.Name, Attribute: attribute.Name})
+			}
+		}
+	}
+	return removals
+}
+
+// orphanedData returns a few of the tuples and attributes in the tenant that
+// the removal would leave without a schema, written out as Permify shows
+// them, or nothing when there are none.
+func orphanedData(ctx context.Context, client *permify_grpc.Client, tenantID string, removal schemaRemoval) ([]string, error) {
+	var filters []*permify_payload.TupleFilter
+	switch {
+	case removal.Relation != "":
+		// A removed relation can also be the subject relation of other
+		// tuples, as in `@organization#member`.
+		filters = append(filters,
+			&permify_payload.TupleFilter{
+				Entity:   &permify_payload.EntityFilter{Type: removal.Entity},
+				Relation: removal.Relation,
+			},
+			&permify_payload.TupleFilter{
+				Subject: &permify_payload.SubjectFilter{Type: removal.Entity, Relation: removal.Relation},
+			},
+		)
+	case removal.Attribute == "":
+		// A removed entity can still be the subject of other entities' tuples.
+		filters = append(filters,
+			&permify_payload.TupleFilter{Entity: &permify_payload.EntityFilter{Type: removal.Entity}},
+			&permify_payload.TupleFilter{Subject: &permify_payload.SubjectFilter{Type: removal.Entity}},
+		)
+	}
+
+	var found []string
+	seen := map[string]bool{}
+	for _, filter := range filters {
+		result, err := client.Data.ReadRelationships(ctx, &permify_payload.RelationshipReadRequest{
+			TenantId: tenantID,
+			Metadata: &permify_payload.RelationshipReadRequestMetadata{},
+			Filter:   filter,
+			PageSize: orphanSampleSize,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, tuple := range result.Tuples {
+			if written := tupleString(tuple); !seen[written] {
+				seen[written] = true
+				found = append(found, written)
+			}
+		}
+	}
+
+	if removal.Relation == "" {
+		filter := &permify_payload.AttributeFilter{Entity: &permify_payload.EntityFilter{Type: removal.Entity}}
+		if removal.Attribute != "" {
+			filter.Attributes = []string{removal.Attribute}
+		}
+		result, err := client.Data.ReadAttributes(ctx, &permify_payload.AttributeReadRequest{
+			TenantId: tenantID,
+			Metadata: &permify_payload.AttributeReadRequestMetadata{},
+			Filter:   filter,
+			PageSize: orphanSampleSize,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, attribute := range result.Attributes {
+			found = append(found, fmt.Sprintf("%s:%s$%s", attribute.GetEntity().GetType(), attribute.GetEntity().GetId(), attribute.GetAttribute()))
+		}
+	}
+
+	if len(found) > orphanSampleSize {
+		found = found[:orphanSampleSize]
+	}
+	return found, nil
+}
+
+// tupleString writes a tuple the way Permify does, as in
+// `document:1#owner@user:2`.
+func tupleString(tuple *permify_payload.Tuple) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s:%s#%s@%s:%s", tuple.GetEntity().GetType(), tuple.GetEntity().GetId(), tuple.GetRelation(),
+		tuple.GetSubject().GetType(), tuple.GetSubject().GetId())
+	if tuple.GetSubject().GetRelation() != "" {
+		fmt.Fprintf(&b, "#%s", tuple.GetSubject().GetRelation())
+	}
+	return b.String()
+}
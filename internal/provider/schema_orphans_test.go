@@ -0,0 +1,114 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	permify_payload "buf.build/gen/go/permifyco/permify/protocolbuffers/go/base/v1"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchemaRemovals(t *testing.T) {
+	old, err := parseSchema(testSchemaDefinition)
+	require.NoError(t, err)
+	updated, err := parseSchema(`
+entity user {}
+
+entity organization {
+    relation admin @user
+    relation member @user
+    attribute public boolean
+    action leave = member
+}
+`)
+	require.NoError(t, err)
+
+	require.Equal(t, []schemaRemoval{
+		{Entity: "repository"},
+	}, schemaRemovals(old, updated))
+	require.Equal(t, []schemaRemoval{
+		{Entity: "organization", Attribute: "public"},
+	}, schemaRemovals(updated, mustParseSchema(t, `
+entity user {}
+
+entity organization {
+    relation admin @user
+    relation member @user
+}
+`)))
+	require.Equal(t, []schemaRemoval{
+		{Entity: "organization", Relation: "member"},
+	}, schemaRemovals(updated, mustParseSchema(t, `
+entity user {}
+
+entity organization {
+    relation admin @user
+    attribute public boolean
+}
+`)))
+}
+
+func mustParseSchema(t *testing.T, text string) *dslSchema {
+	schema, err := parseSchema(text)
+	require.NoError(t, err)
+	return schema
+}
+
+func TestOrphanedData(t *testing.T) {
+	ctx := context.Background()
+	fake := startFakePermify(t)
+	client := newRetryTestClient(t, fake, 0)
+
+	tuple := func(entityType, id, relation, subjectType, subjectID, subjectRelation string) *permify_payload.Tuple {
+		return &permify_payload.Tuple{
+			Entity:   &permify_payload.Entity{Type: entityType, Id: id},
+			Relation: relation,
+			Subject:  &permify_payload.Subject{Type: subjectType, Id: subjectID, Relation: subjectRelation},
+		}
+	}
+	fake.data.add("orphans", []*permify_payload.Tuple{
+		tuple("repository", "1", "owner", "user", "1", ""),
+		tuple("repository", "1", "maintainer", "organization", "1", "member"),
+		tuple("repository", "2", "maintainer", "user", "2", ""),
+		tuple("organization", "1", "member", "user", "3", ""),
+	}, []*permify_payload.Attribute{
+		{Entity: &permify_payload.Entity{Type: "organization", Id: "1"}, Attribute: "public"},
+	})
+
+	tests := []struct {
+		removal schemaRemoval
+		want    []string
+	}{
+		{
+			removal: schemaRemoval{Entity: "repository", Relation: "maintainer"},
+			want:    []string{"repository:1#maintainer@organization:1#member", "repository:2#maintainer@user:2"},
+		},
+		{
+			removal: schemaRemoval{Entity: "repository", Relation: "reader"},
+		},
+		{
+			removal: schemaRemoval{Entity: "organization", Relation: "member"},
+			want:    []string{"organization:1#member@user:3", "repository:1#maintainer@organization:1#member"},
+		},
+		{
+			removal: schemaRemoval{Entity: "organization", Attribute: "public"},
+			want:    []string{"organization:1$public"},
+		},
+		{
+			removal: schemaRemoval{Entity: "organization"},
+			want:    []string{"organization:1#member@user:3", "repository:1#maintainer@organization:1#member", "organization:1$public"},
+		},
+		{
+			removal: schemaRemoval{Entity: "user"},
+			want:    []string{"repository:1#owner@user:1", "repository:2#maintainer@user:2", "organization:1#member@user:3"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.removal.String(), func(t *testing.T) {
+			found, err := orphanedData(ctx, client, "orphans", tt.removal)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, found)
+		})
+	}
+}
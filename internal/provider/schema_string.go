@@ -0,0 +1,119 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+var _ basetypes.StringTypable = SchemaStringType{}
+var _ basetypes.StringValuableWithSemanticEquals = SchemaStringValue{}
+
+// SchemaStringType is the type of schema text.  Its values are equal when
+// they compile to the same schema, so that reformatting a schema, reordering
+// its declarations or editing its comments does not change the plan.
+type SchemaStringType struct {
+	basetypes.StringType
+}
+
+func (t SchemaStringType) Equal(o attr.Type) bool {
+	other, ok := o.(SchemaStringType)
+	if !ok {
+		return false
+	}
+	return t.StringType.Equal(other.StringType)
+}
+
+func (t SchemaStringType) String() string {
+	return "SchemaStringType"
+}
+
+func (t SchemaStringType) ValueFromString(ctx context.Context, in basetypes.StringValue) (basetypes.StringValuable, diag.Diagnostics) {
+	return SchemaStringValue{StringValue: in}, nil
+}
+
+func (t SchemaStringType) ValueFromTerraform(ctx context.Context, in tftypes.Value) (attr.Value, error) {
+	attrValue, err := t.StringType.ValueFromTerraform(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	stringValue, ok := attrValue.(basetypes.StringValue)
+	if !ok {
+		return nil, fmt.Errorf("unexpected value type of %T", attrValue)
+	}
+	return SchemaStringValue{StringValue: stringValue}, nil
+}
+
+func (t SchemaStringType) ValueType(ctx context.Context) attr.Value {
+	return SchemaStringValue{}
+}
+
+type SchemaStringValue struct {
+	basetypes.StringValue
+}
+
+func NewSchemaStringValue(value string) SchemaStringValue {
+	return SchemaStringValue{StringValue: basetypes.NewStringValue(value)}
+}
+
+func NewSchemaStringUnknown() SchemaStringValue {
+	return SchemaStringValue{StringValue: basetypes.NewStringUnknown()}
+}
+
+func (v SchemaStringValue) Equal(o attr.Value) bool {
+	other, ok := o.(SchemaStringValue)
+	if !ok {
+		return false
+	}
+	return v.StringValue.Equal(other.StringValue)
+}
+
+func (v SchemaStringValue) Type(ctx context.Context) attr.Type {
+	return SchemaStringType{}
+}
+
+// StringSemanticEquals compares the parsed schemas.  Text that does not parse
+// is left for Permify to reject, and only equals identical text.
+func (v SchemaStringValue) StringSemanticEquals(ctx context.Context, newValuable basetypes.StringValuable) (bool, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	newValue, ok := newValuable.(SchemaStringValue)
+	if !ok {
+		diags.AddError(
+			"Semantic Equality Check Error",
+			fmt.Sprintf("Expected value type %T but got value type %T. Please report this to the provider developers.", v, newValuable),
+		)
+		return false, diags
+	}
+
+	return schemasEquivalent(v.ValueString(), newValue.ValueString()), diags
+}
+
+// keepEquivalentSchema plans the schema in state when the configuration only
+// differs from it cosmetically, which Terraform accepts as the provider
+// considering the two values equal.
+type keepEquivalentSchema struct{}
+
+var _ planmodifier.String = keepEquivalentSchema{}
+
+func (m keepEquivalentSchema) Description(ctx context.Context) string {
+	return "Keeps the schema in state when the configured schema compiles to the same schema."
+}
+
+func (m keepEquivalentSchema) MarkdownDescription(ctx context.Context) string {
+	return m.Description(ctx)
+}
+
+func (m keepEquivalentSchema) PlanModifyString(ctx context.Context, req planmodifier.StringRequest, resp *planmodifier.StringResponse) {
+	if req.StateValue.IsNull() || req.PlanValue.IsNull() || req.PlanValue.IsUnknown() {
+		return
+	}
+	if schemasEquivalent(req.StateValue.ValueString(), req.PlanValue.ValueString()) {
+		resp.PlanValue = req.StateValue
+	}
+}
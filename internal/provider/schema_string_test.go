@@ -0,0 +1,174 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/stretchr/testify/require"
+)
+
+// testWrittenSchema is testCompiledSchema as someone might write it.
+const testWrittenSchema = `
+// Users are only ever subjects.
+entity user {
+}
+
+entity repository {
+  relation parent @organization
+  relation owner  @user
+
+  /* Members of the organization
+     cannot delete repositories. */
+  action delete = parent.admin or owner not parent.member
+}
+
+entity organization {
+	relation member @organization#admin @user
+	relation admin @user
+	relation viewer @user
+
+	attribute tags string[]
+	attribute credit integer
+
+	permission view = viewer or (member or admin)
+	permission edit = admin and check_credit(credit)
+}
+
+rule check_credit(credit integer) {
+	credit>5000
+}
+`
+
+func TestParseSchema(t *testing.T) {
+	// Rendering what was parsed gives back rendered text unchanged.
+	compiled, err := parseSchema(testCompiledSchema)
+	require.NoError(t, err)
+	require.Equal(t, testCompiledSchema, compiled.String())
+
+	written, err := parseSchema(testWrittenSchema)
+	require.NoError(t, err)
+	written.sortDeclarations()
+	compiled.sortDeclarations()
+	require.Equal(t, compiled.String(), written.String())
+}
+
+func TestParseSchemaErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		want   string
+	}{
+		{
+			name:   "unknown statement",
+			schema: "entity user {\n    relations owner @user\n}\n",
+			want:   `line 2, column 5: expected "relation", "attribute", "permission" or "}", found "relations"`,
+		},
+		{
+			name:   "relation without types",
+			schema: "entity user {\n    relation owner\n}\n",
+			want:   `line 3, column 1: expected "@", found "}"`,
+		},
+		{
+			name:   "unknown attribute type",
+			schema: "entity user {\n    attribute age number\n}\n",
+			want:   `line 2, column 19: unknown attribute type "number"`,
+		},
+		{
+			name:   "unclosed entity",
+			schema: "entity user {\n",
+			want:   `line 2, column 1: expected "relation", "attribute", "permission" or "}", found end of schema`,
+		},
+		{
+			name:   "invalid rule body",
+			schema: "rule check(a integer) {\n    a >\n}\n",
+			want:   "line 1, column 23: body of rule check:",
+		},
+		{
+			name:   "unclosed comment",
+			schema: "entity user {}\n/* user",
+			want:   "line 2, column 1: comment is not closed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseSchema(tt.schema)
+			require.ErrorContains(t, err, tt.want)
+		})
+	}
+}
+
+func TestSchemaStringSemanticEquals(t *testing.T) {
+	tests := []struct {
+		name string
+		a    string
+		b    string
+		want bool
+	}{
+		{
+			name: "reformatted",
+			a:    testCompiledSchema,
+			b:    testWrittenSchema,
+			want: true,
+		},
+		{
+			name: "trailing newline",
+			a:    "entity user {}",
+			b:    "entity user {}\n\n",
+			want: true,
+		},
+		{
+			name: "permission changed",
+			a:    "entity user {\n    relation friend @user\n    permission view = friend\n}\n",
+			b:    "entity user {\n    relation friend @user\n    permission edit = friend\n}\n",
+		},
+		{
+			name: "exclusion reversed",
+			a:    "entity doc {\n    relation a @user\n    relation b @user\n    permission view = a not b\n}\n",
+			b:    "entity doc {\n    relation a @user\n    relation b @user\n    permission view = b not a\n}\n",
+		},
+		{
+			name: "operators grouped differently",
+			a:    "entity doc {\n    relation a @user\n    relation b @user\n    permission view = a or b and a\n}\n",
+			b:    "entity doc {\n    relation a @user\n    relation b @user\n    permission view = a or (b and a)\n}\n",
+		},
+		{
+			name: "rule changed",
+			a:    "rule check(a integer) {\n    a > 1\n}\n",
+			b:    "rule check(a integer) {\n    a > 2\n}\n",
+		},
+		{
+			name: "invalid",
+			a:    "entity user {}\n",
+			b:    "entity user {\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			equal, diags := NewSchemaStringValue(tt.a).StringSemanticEquals(context.Background(), NewSchemaStringValue(tt.b))
+			require.False(t, diags.HasError(), "%v", diags)
+			require.Equal(t, tt.want, equal)
+		})
+	}
+}
+
+func TestKeepEquivalentSchema(t *testing.T) {
+	ctx := context.Background()
+	state := types.StringValue(testCompiledSchema)
+
+	t.Run("cosmetic change", func(t *testing.T) {
+		resp := planmodifier.StringResponse{PlanValue: types.StringValue(testWrittenSchema)}
+		keepEquivalentSchema{}.PlanModifyString(ctx, planmodifier.StringRequest{StateValue: state, PlanValue: resp.PlanValue}, &resp)
+		require.Equal(t, state, resp.PlanValue)
+	})
+
+	t.Run("real change", func(t *testing.T) {
+		plan := types.StringValue("entity user {}\n")
+		resp := planmodifier.StringResponse{PlanValue: plan}
+		keepEquivalentSchema{}.PlanModifyString(ctx, planmodifier.StringRequest{StateValue: state, PlanValue: plan}, &resp)
+		require.Equal(t, plan, resp.PlanValue)
+	})
+}
@@ -5,11 +5,21 @@ import (
 	"fmt"
 	"testing"
 
+	permify_grpc "github.com/Permify/permify-go/grpc"
 	"github.com/stretchr/testify/require"
 	permifytest "github.com/theoriginalstove/testcontainers-permify"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
 )
 
 func initPermify(t *testing.T) string {
+	providerConfig, _ := initPermifyWithEndpoint(t)
+	return providerConfig
+}
+
+// initPermifyWithEndpoint is initPermify for tests that also call Permify
+// themselves, to check on what the provider did.
+func initPermifyWithEndpoint(t *testing.T) (string, string) {
 	ctx := context.Background()
 	container, err := permifytest.Run(ctx)
 	require.NoError(t, err)
@@ -23,9 +33,18 @@ func initPermify(t *testing.T) string {
 	port, err := container.GRPCPort(ctx)
 	require.NoError(t, err)
 
+	endpoint := fmt.Sprintf("%s:%d", host, port)
 	return fmt.Sprintf(`
 	provider "permify" {
-		endpoint = "%s:%d"
+		endpoint = "%s"
 	}
-	`, host, port)
+	`, endpoint), endpoint
+}
+
+func testPermifyClient(t *testing.T, endpoint string) *permify_grpc.Client {
+	client, err := permify_grpc.NewClient(permify_grpc.Config{Endpoint: endpoint},
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	return client
 }